@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/common/hexutil"
+	"github.com/core-coin/go-core/v2/crypto"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/logger"
+)
+
+type approvalUsecase struct {
+	logger logger.Logger
+}
+
+// NewApprovalUsecase create new approval usecase
+func NewApprovalUsecase(log logger.Logger) domain.ApprovalUseCase {
+	return &approvalUsecase{
+		logger: log,
+	}
+}
+
+// BatchHash is hashing raw transactions of a batch in their order.
+// Signed transactions are RLP encoded, so their concatenation is unambiguous.
+func (a *approvalUsecase) BatchHash(signedTxs []string) (string, error) {
+	if len(signedTxs) == 0 {
+		return "", errors.New("cannot hash an empty batch")
+	}
+	var data []byte
+	for i, tx := range signedTxs {
+		raw, err := hexutil.Decode(strings.TrimSpace(tx))
+		if err != nil {
+			return "", fmt.Errorf("transaction %v: %v", i+1, err)
+		}
+		data = append(data, raw...)
+	}
+	return crypto.SHA3Hash(data).Hex(), nil
+}
+
+// Approve is signing batch hash with approver key
+func (a *approvalUsecase) Approve(signedTxs []string, key *crypto.PrivateKey) (*domain.Approval, error) {
+	if key == nil {
+		return nil, errors.New("private key is required to approve a batch")
+	}
+	hash, err := a.BatchHash(signedTxs)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(hexutil.MustDecode(hash), key)
+	if err != nil {
+		return nil, err
+	}
+	approval := &domain.Approval{
+		BatchHash: hash,
+		Approver:  hexutil.Encode(key.PublicKey()[:]),
+		Signature: hexutil.Encode(sig),
+	}
+	a.logger.Debugf("Approved batch %v by %v", approval.BatchHash, approval.Approver)
+	return approval, nil
+}
+
+// VerifyApprovals is counting valid approvals of distinct known approvers, at least one approval is required
+func (a *approvalUsecase) VerifyApprovals(signedTxs []string, approvals []*domain.Approval, approvers []string, required int) error {
+	if required < 1 {
+		return fmt.Errorf("required approvals must be at least 1, not %v", required)
+	}
+	hash, err := a.BatchHash(signedTxs)
+	if err != nil {
+		return err
+	}
+	hashBytes := hexutil.MustDecode(hash)
+
+	known := map[string]bool{}
+	for _, approver := range approvers {
+		known[strings.ToLower(approver)] = true
+	}
+
+	approved := map[string]bool{}
+	for _, approval := range approvals {
+		approver := strings.ToLower(approval.Approver)
+		if !strings.EqualFold(approval.BatchHash, hash) {
+			a.logger.Debugf("Skipping approval of %v for another batch %v", approver, approval.BatchHash)
+			continue
+		}
+		if !known[approver] {
+			a.logger.Warnf("Skipping approval of unknown approver %v", approver)
+			continue
+		}
+		pub, err := hexutil.Decode(approver)
+		if err != nil {
+			a.logger.Warnf("Skipping approval with bad approver key %v: %v", approver, err)
+			continue
+		}
+		sig, err := hexutil.Decode(approval.Signature)
+		if err != nil || len(sig) != crypto.ExtendedSignatureLength || !bytes.Equal(sig[crypto.SignatureLength:], pub) || !crypto.VerifySignature(pub, hashBytes, sig) {
+			a.logger.Warnf("Skipping invalid signature of approver %v", approver)
+			continue
+		}
+		approved[approver] = true
+	}
+
+	if len(approved) < required {
+		return fmt.Errorf("batch %v has %v valid approvals, %v required", hash, len(approved), required)
+	}
+	a.logger.Infof("Batch %v has %v valid approvals", hash, len(approved))
+	return nil
+}
+
+// GetApprovalsFromFile is getting approvals from file
+func (a *approvalUsecase) GetApprovalsFromFile(fileName string) ([]*domain.Approval, error) {
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*domain.Approval{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var approvals []*domain.Approval
+	err = json.Unmarshal(data, &approvals)
+	if err != nil {
+		return nil, err
+	}
+	return approvals, nil
+}
+
+// WriteApprovalsToFile is writing approvals to file
+func (a *approvalUsecase) WriteApprovalsToFile(approvals []*domain.Approval, fileName string) error {
+	data, err := json.MarshalIndent(approvals, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, data, 0644)
+}
+
+// GetApproversFromFile is getting approver public keys from file, empty lines and lines starting with # are skipped
+func (a *approvalUsecase) GetApproversFromFile(fileName string) ([]string, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	var approvers []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pub, err := hexutil.Decode(line)
+		if err != nil {
+			return nil, fmt.Errorf("bad approver public key %v: %v", line, err)
+		}
+		if len(pub) != crypto.PubkeyLength {
+			return nil, fmt.Errorf("approver public key %v must be %v bytes long", line, crypto.PubkeyLength)
+		}
+		approvers = append(approvers, line)
+	}
+	return approvers, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	approvaluc "github.com/core-coin/pigeon/approval/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// approveCmd adds approver's signature to a batch of signed transactions
+var approveCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Approve a batch of signed transactions",
+	Long:  `This command adds approver's ed448 signature over the canonical hash of a batch of signed transactions`,
+	Run: func(cmd *cobra.Command, args []string) {
+		approve()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(approveCmd)
+}
+
+func approve() {
+	logger := newLogger()
+
+	if signedTxFileFlag == "" {
+		logger.Fatal("File with signed transactions is not set, use flag --stream-file")
+	}
+	privateKey, err := getSigningKey()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if privateKey == nil {
+		logger.Fatal("Approver key is not set, use flag --utc-file or --private-key-file")
+	}
+
+	uc := txlistuc.NewTransactionListUsecase(nil, logger, nil, nil, nil, nil)
+	approvalUC := approvaluc.NewApprovalUsecase(logger)
+
+	txList, _, err := uc.GetSignedTxsFromFile(signedTxFileFlag)
+	if err != nil {
+		logger.Fatalf("Error on getting signed transactions from file: %v", err)
+	}
+	approval, err := approvalUC.Approve(txList, privateKey)
+	if err != nil {
+		logger.Fatalf("Error on approving transactions: %v", err)
+	}
+
+	approvalsFile := getApprovalsFile()
+	if approvalsFile == "" {
+		logger.Fatal("Approvals file is not set, use flag --approvals-file")
+	}
+	approvals, err := approvalUC.GetApprovalsFromFile(approvalsFile)
+	if err != nil {
+		logger.Fatalf("Error on getting approvals from file: %v", err)
+	}
+	// replace previous approval of the same approver for the same batch
+	for i, existing := range approvals {
+		if strings.EqualFold(existing.Approver, approval.Approver) && strings.EqualFold(existing.BatchHash, approval.BatchHash) {
+			approvals = append(approvals[:i], approvals[i+1:]...)
+			break
+		}
+	}
+	approvals = append(approvals, approval)
+
+	err = approvalUC.WriteApprovalsToFile(approvals, approvalsFile)
+	if err != nil {
+		logger.Fatalf("Error on writing approvals to file: %v", err)
+	}
+	logger.Infof("Batch %v approved by %v, approvals saved to file %v", approval.BatchHash, approval.Approver, approvalsFile)
+}
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// namePlaceholder is replaced with the name of the input file in outputs of bulk runs
+const namePlaceholder = "{name}"
+
+// txFileExts are extensions of transaction files picked from a directory
+var txFileExts = map[string]bool{".json": true, ".jsonl": true, ".ndjson": true, ".csv": true, ".xlsx": true}
+
+// isBulkInput tells whether input is a directory or a glob of transaction files
+func isBulkInput(fileName string) bool {
+	if fileName == "" || fileName == pkg.Stdio {
+		return false
+	}
+	if strings.ContainsAny(fileName, "*?[") {
+		return true
+	}
+	info, err := os.Stat(fileName)
+	return err == nil && info.IsDir()
+}
+
+// findTxFiles returns transaction files of a directory or files matching a glob in sorted order
+func findTxFiles(input string) ([]string, error) {
+	var files []string
+	info, err := os.Stat(input)
+	if err == nil && info.IsDir() {
+		entries, err := os.ReadDir(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && txFileExts[strings.ToLower(filepath.Ext(entry.Name()))] {
+				files = append(files, filepath.Join(input, entry.Name()))
+			}
+		}
+	} else {
+		matches, err := filepath.Glob(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() {
+				files = append(files, match)
+			}
+		}
+	}
+	sort.Strings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("there are no transaction files in %v", input)
+	}
+	return files, nil
+}
+
+// bulkOutputs checks that outputs of a bulk run name every file after its input, their directories are created
+func bulkOutputs() error {
+	outputs := map[string]string{"output": exportTxFileFlag, "tx-ids-file": signedTxResultFileFlag, "report": reportFileFlag}
+	for flag, value := range outputs {
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, namePlaceholder) {
+			return fmt.Errorf("--%v must contain %v to name outputs after input files, e.g. signed/%v.json", flag, namePlaceholder, namePlaceholder)
+		}
+	}
+	return nil
+}
+
+// signFiles runs every transaction file of the directory or glob of -f as its own batch. Nonces of a sender
+// continue from the previous file, so files signed offline do not reuse them, while spending policy totals start
+// anew for every file. The run stops at the first failed file, and a combined summary of the processed files
+// is printed at the end.
+func signFiles(s *signer, startNonces map[string]uint64) error {
+	err := bulkOutputs()
+	if err != nil {
+		return err
+	}
+	input := txFileFlag
+	files, err := findTxFiles(input)
+	if err != nil {
+		return err
+	}
+	names := map[string]string{}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		if other, ok := names[name]; ok {
+			return fmt.Errorf("files %v and %v would have the same outputs", other, file)
+		}
+		names[name] = file
+	}
+
+	nextNonces := map[string]uint64{}
+	for sender, nonce := range startNonces {
+		nextNonces[pkg.NormalizeAddress(sender)] = nonce
+	}
+	output, txIDsFile, reportFile := exportTxFileFlag, signedTxResultFileFlag, reportFileFlag
+	var (
+		summary *domain.BatchSummary
+		lines   []string
+	)
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		txFileFlag = file
+		exportTxFileFlag = strings.ReplaceAll(output, namePlaceholder, name)
+		signedTxResultFileFlag = strings.ReplaceAll(txIDsFile, namePlaceholder, name)
+		reportFileFlag = strings.ReplaceAll(reportFile, namePlaceholder, name)
+		for _, out := range []string{exportTxFileFlag, signedTxResultFileFlag, reportFileFlag} {
+			if out == "" {
+				continue
+			}
+			err = os.MkdirAll(filepath.Dir(out), 0755)
+			if err != nil {
+				return err
+			}
+		}
+		s.logger.Infof("Processing file %v", file)
+		if s.policyUC != nil {
+			s.policyUC.Reset()
+		}
+
+		txs, err := s.signFile(nextNonces)
+		if txs != nil {
+			fileSummary, sumErr := s.uc.Summarize(txs, largestPayments)
+			if sumErr != nil {
+				return sumErr
+			}
+			if summary == nil {
+				summary = fileSummary
+			} else {
+				summary.Merge(fileSummary, largestPayments)
+			}
+			lines = append(lines, fmt.Sprintf("%v: %v transactions, total %v", file, fileSummary.Count, pkg.FormatOre(fileSummary.Total)))
+			for _, tx := range txs {
+				nonce, parseErr := strconv.ParseUint(tx.Nonce, 10, 64)
+				if parseErr == nil {
+					nextNonces[pkg.NormalizeAddress(tx.From)] = nonce + 1
+				}
+			}
+		}
+		if err != nil {
+			printBulkSummary(lines, summary, len(files))
+			return fmt.Errorf("file %v: %v", file, err)
+		}
+	}
+	printBulkSummary(lines, summary, len(files))
+	return nil
+}
+
+// printBulkSummary writes processed files and their combined summary to standard error
+func printBulkSummary(lines []string, summary *domain.BatchSummary, files int) {
+	fmt.Fprintf(os.Stderr, "Processed %v of %v files:\n", len(lines), files)
+	for _, line := range lines {
+		fmt.Fprintf(os.Stderr, "  %v\n", line)
+	}
+	if summary != nil {
+		printSummary(summary)
+	}
+}
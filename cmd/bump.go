@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/core-coin/go-core/v2/common"
+
+	"github.com/core-coin/pigeon/infrastructure/rpcClient/gocore"
+	replacementuc "github.com/core-coin/pigeon/replacement/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// bumpCmd re-signs stuck transactions with higher energy price
+var bumpCmd = &cobra.Command{
+	Use:   "bump",
+	Short: "Speed up pending transactions",
+	Long:  `This command replaces still pending transactions from tx IDs file with the same transactions at a higher energy price`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bump()
+	},
+}
+
+var bumpFactorFlag float64
+
+func init() {
+	bumpCmd.Flags().Float64Var(&bumpFactorFlag, "bump-factor", 1.2, "Factor to raise energy price of pending transactions by")
+	RootCmd.AddCommand(bumpCmd)
+}
+
+func bump() {
+	logger := newLogger()
+
+	common.DefaultNetworkID = common.NetworkID(networkIDFlag)
+
+	if signedTxResultFileFlag == "" {
+		logger.Fatal("File with transaction IDs is not set, use flag --tx-ids-file")
+	}
+	privateKey, err := getSigningKey()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if privateKey == nil {
+		logger.Fatal("Sender key is not set, use flag --utc-file or --private-key-file")
+	}
+
+	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
+	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger, nil, nil, nil, nil)
+	replacementUC := replacementuc.NewReplacementUsecase(rpcClient, logger)
+
+	txIDs, extras, err := uc.GetTxIDsFromFile(signedTxResultFileFlag)
+	if err != nil {
+		logger.Fatalf("Error on getting transaction IDs from file: %v", err)
+	}
+	replacements, err := replacementUC.BumpTxs(txIDs, privateKey, bumpFactorFlag)
+	if len(replacements) > 0 {
+		// keep tracking the replacement transactions instead of the replaced ones
+		replaced := map[string]string{}
+		for _, r := range replacements {
+			replaced[r.OldHash] = r.NewHash
+		}
+		for i, txID := range txIDs {
+			if newHash, ok := replaced[txID]; ok {
+				txIDs[i] = newHash
+			}
+		}
+		if err := uc.WriteTxIDsToFile(txIDs, extras, signedTxResultFileFlag); err != nil {
+			logger.Fatalf("Error on exporting transaction hashes: %v", err)
+		}
+	}
+	if err != nil {
+		logger.Fatalf("Error on bumping transactions: %v", err)
+	}
+	logger.Infof("Successfully replaced %v pending transactions", len(replacements))
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/core-coin/go-core/v2/common"
+
+	"github.com/core-coin/pigeon/infrastructure/rpcClient/gocore"
+	replacementuc "github.com/core-coin/pigeon/replacement/usecase"
+	reportuc "github.com/core-coin/pigeon/report/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// cancelCmd replaces pending transactions with zero-value self-sends
+var cancelCmd = &cobra.Command{
+	Use:   "cancel",
+	Short: "Cancel pending transactions",
+	Long:  `This command replaces pending transactions of the sender in a nonce range with zero-value self-sends at a higher energy price`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cancel()
+	},
+}
+
+var (
+	fromNonceFlag uint64
+	toNonceFlag   uint64
+)
+
+func init() {
+	cancelCmd.Flags().Uint64Var(&fromNonceFlag, "from-nonce", 0, "First nonce to cancel")
+	cancelCmd.Flags().Uint64Var(&toNonceFlag, "to-nonce", math.MaxUint64, "Last nonce to cancel (default is the last open nonce)")
+	cancelCmd.Flags().Float64Var(&bumpFactorFlag, "bump-factor", 1.2, "Factor to raise energy price of pending transactions by")
+	RootCmd.AddCommand(cancelCmd)
+}
+
+func cancel() {
+	logger := newLogger()
+
+	common.DefaultNetworkID = common.NetworkID(networkIDFlag)
+
+	privateKey, err := getSigningKey()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if privateKey == nil {
+		logger.Fatal("Sender key is not set, use flag --utc-file or --private-key-file")
+	}
+
+	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
+	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger, nil, nil, nil, nil)
+	reportUC := reportuc.NewReportUsecase(pollIntervalFlag, rpcClient, logger)
+	replacementUC := replacementuc.NewReplacementUsecase(rpcClient, logger)
+
+	txList, err := replacementUC.GetCancellationTxs(privateKey.Address().Hex(), fromNonceFlag, toNonceFlag, bumpFactorFlag)
+	if err != nil {
+		logger.Fatalf("Error on getting pending nonces: %v", err)
+	}
+	if len(txList) == 0 {
+		logger.Info("There are no pending transactions to cancel")
+		return
+	}
+	logger.Infof("Cancelling nonces %v-%v of sender %v", txList[0].Nonce, txList[len(txList)-1].Nonce, privateKey.Address().Hex())
+
+	err = confirmBatch(uc, txList, "cancel")
+	if err != nil {
+		logger.Fatal(err)
+	}
+	signedTxs, err := uc.SignTxs(txList, privateKey)
+	if err != nil {
+		logger.Fatalf("Error on signing cancellation transactions: %v", err)
+	}
+	if dryrunFlag {
+		logger.Info("Transactions were not streamed because of dry run!")
+		return
+	}
+	_, err = streamAndExport(logger, uc, reportUC, nil, txList, signedTxs)
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	configuc "github.com/core-coin/pigeon/config/usecase"
+)
+
+// envPrefix starts environment variables overriding flags, e.g. PIGEON_GOCORE
+const envPrefix = "PIGEON_"
+
+// configCmd groups commands working with configuration
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect configuration",
+}
+
+// configShowCmd prints settings after applying config file, profile and environment
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print resolved settings with secrets redacted",
+	Run: func(cmd *cobra.Command, args []string) {
+		showConfig()
+	},
+}
+
+var (
+	configFileFlag string
+	profileFlag    string
+
+	// flagSources tells where the value of every flag came from
+	flagSources = map[string]string{}
+	// resolvedConfigFile is the config file which was applied, if any
+	resolvedConfigFile string
+)
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+	RootCmd.AddCommand(configCmd)
+}
+
+// applyConfig sets flags which are not given on command line from environment, selected profile and config file,
+// in this order of precedence
+func applyConfig(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	settingFlags := map[string]*pflag.Flag{}
+	cmd.Root().PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		name := strings.TrimSpace(flag.Name)
+		if name == "config" || name == "profile" {
+			return
+		}
+		settingFlags[name] = flag
+		flagSources[name] = "default"
+		if flag.Changed {
+			flagSources[name] = "flag"
+		}
+	})
+
+	for name, flag := range settingFlags {
+		value, ok := os.LookupEnv(envName(name))
+		if !ok || flag.Changed {
+			continue
+		}
+		err := flags.Set(flag.Name, value)
+		if err != nil {
+			return fmt.Errorf("bad value of %v: %v", envName(name), err)
+		}
+		flagSources[name] = "env " + envName(name)
+	}
+
+	configFile := configFileFlag
+	if configFile == "" {
+		configFile = os.Getenv(envName("config"))
+	}
+	if configFile == "" {
+		var err error
+		configFile, err = configuc.FindConfigFile()
+		if err != nil {
+			return err
+		}
+	}
+	profile := profileFlag
+	if profile == "" {
+		profile = os.Getenv(envName("profile"))
+	}
+	if configFile == "" {
+		if profile != "" {
+			return fmt.Errorf("profile %q is selected but there is no config file, use flag --config", profile)
+		}
+		return nil
+	}
+
+	config, err := configuc.GetConfigFromFile(configFile)
+	if err != nil {
+		return fmt.Errorf("error on reading config file %v: %v", configFile, err)
+	}
+	resolvedConfigFile = configFile
+	if profile == "" {
+		profile = config.Profile
+	}
+	settings, err := config.Resolve(profile)
+	if err != nil {
+		return err
+	}
+	for name, value := range settings {
+		flag, ok := settingFlags[name]
+		if !ok {
+			return fmt.Errorf("unknown setting %q in config file %v", name, configFile)
+		}
+		if flag.Changed {
+			continue
+		}
+		err = flags.Set(flag.Name, value)
+		if err != nil {
+			return fmt.Errorf("bad value of %v in config file %v: %v", name, configFile, err)
+		}
+		flagSources[name] = "config"
+		if _, ok := config.Profiles[profile][name]; ok {
+			flagSources[name] = "profile " + profile
+		}
+	}
+	return nil
+}
+
+// envName returns environment variable overriding flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(flagName), "-", "_"))
+}
+
+func showConfig() {
+	if resolvedConfigFile != "" {
+		fmt.Printf("# config file: %v\n", resolvedConfigFile)
+	}
+	var names []string
+	values := map[string]string{}
+	RootCmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		name := strings.TrimSpace(flag.Name)
+		if _, ok := flagSources[name]; !ok {
+			return
+		}
+		names = append(names, name)
+		values[name] = flag.Value.String()
+	})
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%v: %v # %v\n", name, redact(name, values[name]), flagSources[name])
+	}
+}
+
+// redact hides credentials in node URL, key and password files are shown by path only
+func redact(name, value string) string {
+	if name != "gocore" {
+		return value
+	}
+	endpoint, err := url.Parse(value)
+	if err != nil {
+		return "REDACTED"
+	}
+	if endpoint.User != nil {
+		endpoint.User = url.User("REDACTED")
+	}
+	query := endpoint.Query()
+	for key := range query {
+		query.Set(key, "REDACTED")
+	}
+	endpoint.RawQuery = query.Encode()
+	return endpoint.String()
+}
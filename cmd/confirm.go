@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const (
+	confirmationPhrase = "CONFIRM"
+	largestPayments    = 5
+)
+
+// confirmBatch shows batch summary and waits for operator to type confirmation phrase unless --yes is set
+func confirmBatch(uc domain.TransactionListUseCase, txs domain.TransactionList, action string) error {
+	summary, err := uc.Summarize(txs, largestPayments)
+	if err != nil {
+		return err
+	}
+	return confirmSummary(summary, action)
+}
+
+// confirmSummary shows summary of a batch and waits for confirmation phrase unless --yes is set
+func confirmSummary(summary *domain.BatchSummary, action string) error {
+	printSummary(summary)
+
+	if yesFlag || dryrunFlag {
+		return nil
+	}
+	if !term.IsTerminal(int(syscall.Stdin)) {
+		return errors.New("cannot ask for confirmation without terminal, use flag --yes")
+	}
+	fmt.Fprintf(os.Stderr, "Type %q to %v %v transactions: \n", confirmationPhrase, action, summary.Count)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(answer) != confirmationPhrase {
+		return errors.New("batch was not confirmed by operator")
+	}
+	return nil
+}
+
+// printSummary writes summary to standard error, standard output may carry data
+func printSummary(summary *domain.BatchSummary) {
+	node := gocoreAddressFlag
+	if node == "" {
+		node = "offline"
+	}
+	fmt.Fprintf(os.Stderr, "Network:      %v\n", networkIDFlag)
+	fmt.Fprintf(os.Stderr, "Node:         %v\n", node)
+	fmt.Fprintf(os.Stderr, "Transactions: %v\n", summary.Count)
+	fmt.Fprintf(os.Stderr, "Total value:  %v\n", pkg.FormatOre(summary.Total))
+	fmt.Fprintf(os.Stderr, "Max fees:     %v\n", pkg.FormatOre(summary.MaxFees))
+
+	senders := make([]string, 0, len(summary.SenderTotals))
+	for sender := range summary.SenderTotals {
+		senders = append(senders, sender)
+	}
+	sort.Strings(senders)
+	fmt.Fprintln(os.Stderr, "Total per sender:")
+	for _, sender := range senders {
+		fmt.Fprintf(os.Stderr, "  %v: %v\n", sender, pkg.FormatOre(summary.SenderTotals[sender]))
+	}
+
+	fmt.Fprintln(os.Stderr, "Largest payments:")
+	for i, tx := range summary.Largest {
+		fmt.Fprintf(os.Stderr, "  %v. %v -> %v: %v\n", i+1, tx.From, tx.To, tx.Amount)
+	}
+}
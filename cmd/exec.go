@@ -1,8 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -14,9 +21,20 @@ import (
 	"github.com/core-coin/go-core/v2/common"
 	"github.com/core-coin/go-core/v2/common/hexutil"
 
+	approvaluc "github.com/core-coin/pigeon/approval/usecase"
 	"github.com/core-coin/pigeon/domain"
+	energypriceuc "github.com/core-coin/pigeon/energy_price/usecase"
+	flowcontroluc "github.com/core-coin/pigeon/flow_control/usecase"
 	"github.com/core-coin/pigeon/infrastructure/rpcClient/gocore"
+	ledgeruc "github.com/core-coin/pigeon/ledger/usecase"
+	"github.com/core-coin/pigeon/logger"
 	"github.com/core-coin/pigeon/logger/zap"
+	nonceuc "github.com/core-coin/pigeon/nonce/usecase"
+	"github.com/core-coin/pigeon/pkg"
+	policyuc "github.com/core-coin/pigeon/policy/usecase"
+	reportuc "github.com/core-coin/pigeon/report/usecase"
+	scheduleuc "github.com/core-coin/pigeon/schedule/usecase"
+	senderpooluc "github.com/core-coin/pigeon/sender_pool/usecase"
 	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
 )
 
@@ -26,63 +44,102 @@ func execute() {
 		err        error
 	)
 
-	if verbosityFlag > 7 {
-		verbosityFlag = 7
-	}
-	logger := zap.NewApiLogger(verbosityFlag)
-	logger.InitLogger()
+	logger := newLogger()
 
 	common.DefaultNetworkID = common.NetworkID(networkIDFlag)
 
-	if privateKeyFileFlag != "" && UTCFileFlag != "" {
-		logger.Fatal("Cannot use both fags for private key and encrypted UTC file")
+	privateKey, err = getSigningKey()
+	if err != nil {
+		logger.Fatal(err)
 		return
 	}
-	if privateKeyFileFlag != "" {
-		privateKey, err = getPrivateKey(privateKeyFileFlag)
-		if err != nil {
-			logger.Fatalf("Error on getting private key from file: %v", err)
-			return
+	poolKeys, err := getPoolKeys()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if len(poolKeys) > 0 {
+		if privateKey != nil {
+			logger.Fatal("Cannot use both sender pool and a single sender key")
 		}
+		parallelFlag = true
 	}
-	if UTCFileFlag != "" {
-		privateKey, err = getPrivateKeyFromUTC(UTCFileFlag, UTCFilePasswordFlag)
+	err = checkSchedule()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
+	var policyUC domain.PolicyUseCase
+	if policyFileFlag != "" {
+		policy, err := policyuc.GetPolicyFromFile(policyFileFlag)
 		if err != nil {
-			logger.Fatalf("Error on getting private key from UTC file: %v", err)
-			return
+			logger.Fatalf("Error on getting spending policy from file: %v", err)
 		}
+		policyUC = policyuc.NewPolicyUsecase(policy, rpcClient, logger)
+	}
+	var nonceUC domain.NonceReservationUseCase
+	if nonceDirFlag != "" {
+		nonceUC = nonceuc.NewNonceReservationUsecase(nonceDirFlag, nonceReservationTTLFlag, rpcClient, logger)
+	}
+	strategy, err := getEnergyPriceStrategy()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	priceUC := energypriceuc.NewEnergyPriceUsecase(strategy, rpcClient, logger)
+	var flowUC domain.FlowControlUseCase
+	if maxInFlightFlag > 0 {
+		flowUC = flowcontroluc.NewFlowControlUsecase(maxInFlightFlag, pollIntervalFlag, rpcClient, logger)
+	}
+	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger, policyUC, nonceUC, priceUC, flowUC)
+	approvalUC := approvaluc.NewApprovalUsecase(logger)
+	scheduleUC := scheduleuc.NewScheduleUsecase(pollIntervalFlag, rpcClient, logger)
+	reportUC := reportuc.NewReportUsecase(pollIntervalFlag, rpcClient, logger)
+	ledgerUC, err := getLedger(logger)
+	if err != nil {
+		logger.Fatal(err)
 	}
-	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
-	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger)
 
 	// Get signed transactions from file and stream them
 	{
 		if signedTxFileFlag != "" {
-			txList, err := uc.GetSignedTxsFromFile(signedTxFileFlag)
+			txList, extras, err := uc.GetSignedTxsFromFile(signedTxFileFlag)
 			if err != nil {
 				logger.Fatalf("Error on getting signed transactions from file: %v", err)
 			}
 			logger.Infof("Successfully got signed transactions from file %v", signedTxFileFlag)
+			err = checkApprovals(approvalUC, txList)
+			if err != nil {
+				logger.Fatalf("Error on checking approvals: %v", err)
+			}
+			decodedTxs, err := uc.DecodeSignedTxs(txList)
+			if err != nil {
+				logger.Fatalf("Error on decoding signed transactions: %v", err)
+			}
+			for i, extra := range extras {
+				decodedTxs[i].Extra = extra
+			}
+			err = checkDuplicates(logger, ledgerUC, decodedTxs, 0)
+			if err != nil {
+				logger.Fatal(err)
+			}
+			err = confirmBatch(uc, decodedTxs, "stream")
+			if err != nil {
+				logger.Fatal(err)
+			}
 			if !dryrunFlag {
-				txIDs, err := uc.StreamSignedTxs(txList)
+				err = waitBeforeStreaming(scheduleUC)
 				if err != nil {
-					logger.Errorf("Error on streaming transactions to blockchain: %v", err)
-					if len(txIDs) > 0 {
-						logger.Error("But some transactions were streamed before error:")
-						for i, txID := range txIDs {
-							logger.Errorf("%v: %v", i+1, txID)
-						}
-					}
-					return
+					logger.Fatalf("Error on waiting to stream transactions: %v", err)
 				}
-				logger.Info("Successfully streamed signed transactions into blockchain")
-
-				err = exportTxIDs(uc, txIDs, signedTxResultFileFlag)
+				_, err = streamAndExport(logger, uc, reportUC, ledgerUC, decodedTxs, txList)
 				if err != nil {
-					logger.Fatalf("Error on exporting transaction hashes: %v", err)
+					logger.Fatal(err)
 				}
 			} else {
 				logger.Info("Transactions were not streamed because of dry run!")
+				err = writeSignedReport(reportUC, decodedTxs, txList)
+				if err != nil {
+					logger.Fatalf("Error on writing results report: %v", err)
+				}
 			}
 			return
 		}
@@ -91,62 +148,827 @@ func execute() {
 	//Get transactions from file, sign them and then stream
 	{
 		// Get transactions
-		txList, err := uc.GetTxsFromFile(txFileFlag, titlesFlag)
+		startNonces, err := getStartNonces()
 		if err != nil {
-			logger.Fatalf("Error on getting transactions from file: %v", err)
+			logger.Fatal(err)
 		}
-		logger.Infof("Successfully got transactions from file %v", txFileFlag)
-		// Sign transactions
-		signedTxs, err := uc.SignTxs(txList, privateKey)
+		csvFormat, err := getCSVFormat()
 		if err != nil {
-			logger.Fatalf("Error on signing transactions from file: %v", err)
+			logger.Fatal(err)
 		}
-		logger.Info("Successfully signed transactions")
-
-		// Save signed transactions into a file if needed
-		if exportTxFileFlag != "" {
-			err = uc.WriteSignedTxsToFile(signedTxs, exportTxFileFlag)
+		if !isBulkInput(txFileFlag) && pkg.IsJSONLines(pkg.InputExt(txFileFlag)) && pkg.IsJSONLines(pkg.OutputExt(exportTxFileFlag)) {
+			if len(poolKeys) > 0 {
+				logger.Fatal("Sender pool needs the whole batch, it cannot be used with JSON lines input and output")
+			}
+			if reportFileFlag != "" {
+				logger.Fatal("Results report needs the whole batch, it cannot be used with JSON lines input and output")
+			}
+			err = signInChunks(logger, uc, nonceUC, csvFormat, startNonces, privateKey)
 			if err != nil {
-				logger.Fatalf("Error on writing signed transactions to file: %v", err)
+				logger.Fatal(err)
 			}
-			logger.Infof("Successfully saved signed transactions into a file %v", exportTxFileFlag)
 			return
 		}
+		s := &signer{
+			logger:     logger,
+			uc:         uc,
+			approvalUC: approvalUC,
+			scheduleUC: scheduleUC,
+			reportUC:   reportUC,
+			ledgerUC:   ledgerUC,
+			policyUC:   policyUC,
+			poolUC:     senderpooluc.NewSenderPoolUsecase(rpcClient, priceUC, logger),
+			poolKeys:   poolKeys,
+			privateKey: privateKey,
+			csvFormat:  csvFormat,
+		}
+		if isBulkInput(txFileFlag) {
+			err = signFiles(s, startNonces)
+		} else {
+			_, err = s.signFile(startNonces)
+		}
+		if err != nil {
+			logger.Fatal(err)
+		}
+	}
+}
 
-		// Stream signed transactions
-		if !dryrunFlag {
-			txIDs, err := uc.StreamSignedTxs(signedTxs)
-			if err != nil {
-				logger.Errorf("Error on streaming transactions to blockchain: %v", err)
-				if len(txIDs) > 0 {
-					logger.Error("But some transactions were streamed before error:")
-					for i, txID := range txIDs {
-						logger.Errorf("%v: %v", i+1, txID)
-					}
-				}
+// signer holds usecases and keys shared by transaction files of a run
+type signer struct {
+	logger     logger.Logger
+	uc         domain.TransactionListUseCase
+	approvalUC domain.ApprovalUseCase
+	scheduleUC domain.ScheduleUseCase
+	reportUC   domain.ReportUseCase
+	ledgerUC   domain.LedgerUseCase
+	policyUC   domain.PolicyUseCase
+	poolUC     domain.SenderPoolUseCase
+	poolKeys   map[string]*crypto.PrivateKey
+	privateKey *crypto.PrivateKey
+	csvFormat  *domain.CSVFormat
+}
+
+// signFile is getting transactions from file, signing them and then saving or streaming them.
+// Nonces are reserved once the batch is confirmed and signed, not in dry run. They are kept once the batch is saved
+// or a transaction of it is streamed, and released if it fails before that.
+// Returns signed transactions, also when streaming fails, nil if nothing was signed
+func (s *signer) signFile(startNonces map[string]uint64) (txList domain.TransactionList, err error) {
+	defer func() {
+		if err != nil {
+			s.uc.ReleaseNonces()
+		}
+	}()
+	txList, err = s.uc.ReadTxsFromFile(txFileFlag, s.csvFormat)
+	if err != nil {
+		return nil, fmt.Errorf("error on getting transactions from file: %v", err)
+	}
+	if len(s.poolKeys) > 0 {
+		// Senders are assigned from the pool before nonces are filled
+		err = s.poolUC.AssignSenders(txList, poolSenders(s.poolKeys))
+		if err != nil {
+			return nil, fmt.Errorf("error on assigning senders from the pool: %v", err)
+		}
+	}
+	// nonces of rows without sender are the nonces of the key
+	txList, err = s.uc.FillTxs(withSender(txList, s.privateKey), startNonces)
+	if err != nil {
+		return nil, fmt.Errorf("error on getting transactions from file: %v", err)
+	}
+	s.logger.Infof("Successfully got transactions from file %v", txFileFlag)
+	err = s.uc.CheckNonces(txList)
+	if err != nil {
+		return nil, err
+	}
+	err = checkDuplicates(s.logger, s.ledgerUC, txList, 0)
+	if err != nil {
+		return nil, err
+	}
+	err = confirmBatch(s.uc, txList, "sign")
+	if err != nil {
+		return nil, err
+	}
+	// Sign transactions
+	var signedTxs []string
+	if len(s.poolKeys) > 0 {
+		signedTxs, err = s.uc.SignTxsWithKeys(txList, s.poolKeys)
+	} else {
+		signedTxs, err = s.uc.SignTxs(txList, s.privateKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error on signing transactions from file: %v", err)
+	}
+	s.logger.Info("Successfully signed transactions")
+	if !dryrunFlag {
+		err = s.uc.ReserveNonces()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Save signed transactions into a file if needed
+	if exportTxFileFlag != "" {
+		err = s.uc.WriteSignedTxsToFile(signedTxs, txList.Extras(), exportTxFileFlag)
+		if err != nil {
+			return nil, fmt.Errorf("error on writing signed transactions to file: %v", err)
+		}
+		s.logger.Infof("Successfully saved signed transactions into a file %v", exportTxFileFlag)
+		err = s.uc.KeepNonces()
+		if err != nil {
+			return txList, err
+		}
+		err = writeSignedReport(s.reportUC, txList, signedTxs)
+		if err != nil {
+			return txList, fmt.Errorf("error on writing results report: %v", err)
+		}
+		return txList, nil
+	}
+
+	err = checkApprovals(s.approvalUC, signedTxs)
+	if err != nil {
+		return nil, fmt.Errorf("error on checking approvals: %v", err)
+	}
+
+	// Stream signed transactions
+	if dryrunFlag {
+		s.logger.Info("Transactions were not streamed because of dry run!")
+		err = writeSignedReport(s.reportUC, txList, signedTxs)
+		if err != nil {
+			return txList, fmt.Errorf("error on writing results report: %v", err)
+		}
+		return txList, nil
+	}
+	// reservation must not expire while the run waits for its time, block or energy price
+	stopRefresh := refreshNonces(s.uc)
+	err = waitBeforeStreaming(s.scheduleUC)
+	if err != nil {
+		stopRefresh()
+		return nil, fmt.Errorf("error on waiting to stream transactions: %v", err)
+	}
+	streamed, err := streamAndExport(s.logger, s.uc, s.reportUC, s.ledgerUC, txList, signedTxs)
+	stopRefresh()
+	if streamed > 0 {
+		keepErr := s.uc.KeepNonces()
+		if keepErr != nil {
+			s.logger.Error(keepErr)
+		}
+	}
+	return txList, err
+}
+
+// refreshNonces is renewing nonces reserved by uc every poll interval until the returned function is called
+func refreshNonces(uc domain.TransactionListUseCase) func() {
+	interval := pollIntervalFlag
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
 				return
+			case <-ticker.C:
+				uc.RefreshNonces()
 			}
-			logger.Info("Successfully streamed signed transactions into blockchain")
-			err = exportTxIDs(uc, txIDs, signedTxResultFileFlag)
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// signingChunkSize is the number of transactions held in memory when signing JSON lines files
+const signingChunkSize = 10000
+
+// signInChunks is reading, filling, checking and signing JSON lines input chunk by chunk and appending signed
+// transactions to JSON lines output, so memory does not grow with the batch. Output is written to a partial file
+// which replaces output after the batch summary is confirmed.
+func signInChunks(logger logger.Logger, uc domain.TransactionListUseCase, nonceUC domain.NonceReservationUseCase, csvFormat *domain.CSVFormat, startNonces map[string]uint64, privateKey *crypto.PrivateKey) error {
+	nextNonces := map[string]uint64{}
+	for sender, nonce := range startNonces {
+		nextNonces[pkg.NormalizeAddress(sender)] = nonce
+	}
+	// nonces of the whole batch are planned at once, chunks continue from the previous one,
+	// they are reserved after the batch is signed and confirmed
+	var planned []domain.NonceRange
+	if nonceUC != nil {
+		if txFileFlag == pkg.Stdio {
+			return errors.New("nonces cannot be reserved for JSON lines from standard input, save it to a file or use --start-nonce")
+		}
+		missingNonces := map[string]uint64{}
+		err := uc.ReadTxsInChunks(txFileFlag, csvFormat, signingChunkSize, func(txs domain.TransactionList) error {
+			for _, tx := range withSender(txs, privateKey) {
+				sender := pkg.NormalizeAddress(tx.From)
+				if _, ok := nextNonces[sender]; !ok && tx.Nonce == "" {
+					missingNonces[sender]++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error on getting transactions from file: %v", err)
+		}
+		for sender, count := range missingNonces {
+			nonce, err := nonceUC.NextNonce(sender)
+			if err != nil {
+				return err
+			}
+			nextNonces[sender] = nonce
+			planned = append(planned, domain.NonceRange{Sender: sender, Start: nonce, Count: count})
+		}
+	}
+
+	partialFile, err := getPartialFile()
+	if err != nil {
+		return err
+	}
+	nonceChecker := uc.NewNonceChecker()
+	var summary *domain.BatchSummary
+	err = uc.ReadTxsInChunks(txFileFlag, csvFormat, signingChunkSize, func(txs domain.TransactionList) error {
+		txs, err := uc.FillTxs(withSender(txs, privateKey), nextNonces)
+		if err != nil {
+			return fmt.Errorf("error on getting transactions from file: %v", err)
+		}
+		for _, tx := range txs {
+			nonce, err := strconv.ParseUint(tx.Nonce, 10, 64)
 			if err != nil {
-				logger.Fatalf("Error on exporting transaction hashes: %v", err)
+				return fmt.Errorf("bad nonce %q: %v", tx.Nonce, err)
 			}
+			nextNonces[pkg.NormalizeAddress(tx.From)] = nonce + 1
+		}
+		err = nonceChecker.Check(txs)
+		if err != nil {
+			return err
+		}
+		chunkSummary, err := uc.Summarize(txs, largestPayments)
+		if err != nil {
+			return err
+		}
+		if summary == nil {
+			summary = chunkSummary
 		} else {
-			logger.Info("Transactions were not streamed because of dry run!")
+			summary.Merge(chunkSummary, largestPayments)
 		}
+		signedTxs, err := uc.SignTxs(txs, privateKey)
+		if err != nil {
+			return fmt.Errorf("error on signing transactions from file: %v", err)
+		}
+		err = uc.AppendSignedTxsToFile(signedTxs, txs.Extras(), partialFile)
+		if err != nil {
+			return fmt.Errorf("error on writing signed transactions to file: %v", err)
+		}
+		logger.Infof("Signed %v transactions", summary.Count)
+		return nil
+	})
+	if err == nil {
+		err = nonceChecker.Finish()
+	}
+	if err != nil {
+		os.Remove(partialFile)
+		return err
+	}
+	if summary == nil {
+		logger.Info("There are no transactions in file")
+		return nil
+	}
+
+	err = confirmSummary(summary, "save signed")
+	if err != nil {
+		os.Remove(partialFile)
+		return err
+	}
+	var reserved []domain.NonceRange
+	if !dryrunFlag {
+		for _, r := range planned {
+			err = nonceUC.ReserveNonces(r)
+			if err != nil {
+				releaseNonces(logger, nonceUC, reserved)
+				os.Remove(partialFile)
+				return err
+			}
+			reserved = append(reserved, r)
+		}
+	}
+	if exportTxFileFlag == pkg.Stdio {
+		err = copyToStdout(partialFile)
+		os.Remove(partialFile)
+		if err != nil {
+			releaseNonces(logger, nonceUC, reserved)
+			return err
+		}
+		logger.Info("Successfully wrote signed transactions to standard output")
+		return keepNonces(nonceUC, reserved)
+	}
+	err = os.Rename(partialFile, exportTxFileFlag)
+	if err != nil {
+		releaseNonces(logger, nonceUC, reserved)
+		return err
+	}
+	logger.Infof("Successfully saved signed transactions into a file %v", exportTxFileFlag)
+	return keepNonces(nonceUC, reserved)
+}
+
+// keepNonces keeps reserved ranges of a saved batch until the chain passes them
+func keepNonces(nonceUC domain.NonceReservationUseCase, reserved []domain.NonceRange) error {
+	for _, r := range reserved {
+		err := nonceUC.KeepNonces(r)
+		if err != nil {
+			return fmt.Errorf("cannot keep nonces %v-%v of sender %v: %v", r.Start, r.Start+r.Count-1, r.Sender, err)
+		}
+	}
+	return nil
+}
+
+// releaseNonces gives up reserved ranges of a batch which was not saved
+func releaseNonces(logger logger.Logger, nonceUC domain.NonceReservationUseCase, reserved []domain.NonceRange) {
+	for _, r := range reserved {
+		err := nonceUC.ReleaseNonces(r)
+		if err != nil {
+			logger.Warnf("Cannot release nonces %v-%v of sender %v: %v", r.Start, r.Start+r.Count-1, r.Sender, err)
+		}
+	}
+}
+
+// getPartialFile is choosing an empty file for signed transactions until the batch is confirmed,
+// a temporary file for standard output
+func getPartialFile() (string, error) {
+	if exportTxFileFlag == pkg.Stdio {
+		file, err := os.CreateTemp("", "pigeon-*.partial.jsonl")
+		if err != nil {
+			return "", err
+		}
+		return file.Name(), file.Close()
+	}
+	ext := filepath.Ext(exportTxFileFlag)
+	partialFile := strings.TrimSuffix(exportTxFileFlag, ext) + ".partial" + ext
+	err := os.Remove(partialFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	return partialFile, nil
+}
+
+// copyToStdout is writing file to standard output
+func copyToStdout(fileName string) error {
+	in, err := os.Open(fileName)
+	if err != nil {
+		return err
 	}
+	defer in.Close()
+	_, err = io.Copy(os.Stdout, in)
+	return err
 }
 
-func exportTxIDs(uc domain.TransactionListUseCase, txIDs []string, exportFile string) error {
+// checkSchedule refuses schedule flags for runs which save signed transactions instead of streaming them
+func checkSchedule() error {
+	if streamDeadlineFlag != "" && streamBelowEnergyPriceFlag == "" {
+		return errors.New("--stream-deadline ends waiting for --stream-below-energy-price, it cannot be used without it")
+	}
+	if notBeforeTimeoutFlag != 0 {
+		if _, err := strconv.ParseUint(notBeforeFlag, 10, 64); err != nil {
+			return errors.New("--not-before-timeout limits waiting for a --not-before block, it cannot be used without it")
+		}
+	}
+	if signedTxFileFlag != "" || exportTxFileFlag == "" {
+		return nil
+	}
+	flags := map[string]string{"not-before": notBeforeFlag, "stream-below-energy-price": streamBelowEnergyPriceFlag, "stream-deadline": streamDeadlineFlag}
+	for _, flag := range []string{"not-before", "stream-below-energy-price", "stream-deadline"} {
+		if flags[flag] != "" {
+			return fmt.Errorf("--%v schedules streaming, it cannot be used with -o which only saves signed transactions, use it with -s", flag)
+		}
+	}
+	return nil
+}
+
+// waitBeforeStreaming holds streaming until the scheduled time or block, and then until energy price
+// is low enough or the deadline passes
+func waitBeforeStreaming(uc domain.ScheduleUseCase) error {
+	if notBeforeFlag != "" {
+		if block, err := strconv.ParseUint(notBeforeFlag, 10, 64); err == nil {
+			var deadline time.Time
+			if notBeforeTimeoutFlag > 0 {
+				deadline = time.Now().Add(notBeforeTimeoutFlag)
+			}
+			err = uc.WaitForBlock(block, deadline)
+			if err != nil {
+				return err
+			}
+		} else {
+			notBefore, err := parseTime(notBeforeFlag)
+			if err != nil {
+				return err
+			}
+			err = uc.WaitForTime(notBefore)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if streamBelowEnergyPriceFlag == "" {
+		return nil
+	}
+	maxPrice, ok := new(big.Int).SetString(streamBelowEnergyPriceFlag, 10)
+	if !ok {
+		return fmt.Errorf("bad energy price %q", streamBelowEnergyPriceFlag)
+	}
+	var deadline time.Time
+	if streamDeadlineFlag != "" {
+		var err error
+		deadline, err = parseTime(streamDeadlineFlag)
+		if err != nil {
+			return err
+		}
+	}
+	return uc.WaitForEnergyPrice(maxPrice, deadline)
+}
+
+// parseTime parses RFC3339 time or duration from now
+func parseTime(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(duration), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("bad time %q, use RFC3339 time or duration", value)
+	}
+	return t, nil
+}
+
+// streamAndExport streams signed transactions, records streamed payments in the ledger if it is set and exports
+// IDs of the streamed ones, results report tells the outcome of every row, also of the ones which failed or were not streamed.
+// Returns the number of streamed transactions, also on error
+func streamAndExport(logger logger.Logger, uc domain.TransactionListUseCase, reportUC domain.ReportUseCase, ledgerUC domain.LedgerUseCase, txs domain.TransactionList, signedTxs []string) (int, error) {
+	var results []*domain.Result
+	if reportFileFlag != "" || trackFlag > 0 {
+		var err error
+		results, err = reportUC.GetResults(txs, signedTxs)
+		if err != nil {
+			return 0, fmt.Errorf("error on building results report: %v", err)
+		}
+	}
+
+	hashes, errs := uc.StreamSignedTxsByRow(signedTxs, parallelFlag)
+	if ledgerUC != nil {
+		file := txFileFlag
+		if signedTxFileFlag != "" {
+			file = signedTxFileFlag
+		}
+		err := ledgerUC.Record(txs, hashes, file)
+		if err != nil {
+			logger.Errorf("Error on recording streamed payments in ledger: %v", err)
+		}
+	}
+	var (
+		txIDs    []string
+		messages []string
+	)
+	for i, hash := range hashes {
+		if hash != "" {
+			txIDs = append(txIDs, hash)
+		}
+		if errs[i] != nil {
+			messages = append(messages, errs[i].Error())
+		}
+	}
+
+	// failed tracking is returned after the report with outcomes known so far is written
+	var trackErr error
+	if results != nil {
+		reportUC.SetStreamOutcome(results, hashes, errs)
+		if trackFlag > 0 && len(txIDs) > 0 {
+			err := reportUC.TrackResults(results, trackFlag)
+			if err != nil {
+				trackErr = fmt.Errorf("error on tracking transactions: %v", err)
+				logger.Error(trackErr)
+			}
+		}
+		if reportFileFlag != "" {
+			err := reportUC.WriteResultsToFile(results, reportFileFlag)
+			if err != nil {
+				return len(txIDs), fmt.Errorf("error on writing results report: %v", err)
+			}
+			logger.Infof("Successfully saved results report into a file %v", reportFileFlag)
+		}
+	}
+
+	if len(messages) > 0 {
+		logger.Errorf("Error on streaming transactions to blockchain: %v", strings.Join(messages, "; "))
+		if len(txIDs) > 0 {
+			logger.Error("But some transactions were streamed before error:")
+			for i, txID := range txIDs {
+				logger.Errorf("%v: %v", i+1, txID)
+			}
+		}
+		return len(txIDs), fmt.Errorf("%v of %v transactions were not streamed", len(signedTxs)-len(txIDs), len(signedTxs))
+	}
+	logger.Info("Successfully streamed signed transactions into blockchain")
+
+	// the report lists hashes already, so they are not repeated on console
+	if reportFileFlag != "" && signedTxResultFileFlag == "" {
+		return len(txIDs), trackErr
+	}
+	err := exportTxIDs(uc, txIDs, txs.Extras(), signedTxResultFileFlag)
+	if err != nil {
+		return len(txIDs), fmt.Errorf("error on exporting transaction hashes: %v", err)
+	}
+	return len(txIDs), trackErr
+}
+
+// getLedger builds ledger of streamed payments from flags, nil for runs which only save signed transactions
+func getLedger(logger logger.Logger) (domain.LedgerUseCase, error) {
+	if signedTxFileFlag == "" && exportTxFileFlag != "" {
+		return nil, nil
+	}
+	fileName := ledgerFileFlag
+	if fileName == "" {
+		var err error
+		fileName, err = ledgeruc.DefaultLedgerFile()
+		if err != nil {
+			return nil, fmt.Errorf("cannot find user config directory for the ledger, use flag --ledger: %v", err)
+		}
+	}
+	var key []string
+	for _, field := range idempotencyKeyFlag {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if field != "" {
+			key = append(key, field)
+		}
+	}
+	if len(key) == 0 {
+		return nil, errors.New("idempotency key has no fields")
+	}
+	return ledgeruc.NewLedgerUsecase(fileName, key, logger), nil
+}
+
+// checkDuplicates refuses rows found in the ledger unless duplicates are allowed, then they are only warned about.
+// Rows are numbered from offset + 1, nothing is checked without ledger.
+func checkDuplicates(logger logger.Logger, ledgerUC domain.LedgerUseCase, txs domain.TransactionList, offset int) error {
+	if ledgerUC == nil {
+		return nil
+	}
+	duplicates, err := ledgerUC.FindDuplicates(txs)
+	if err != nil {
+		return fmt.Errorf("error on checking ledger: %v", err)
+	}
+	count := 0
+	for i, entry := range duplicates {
+		if entry == nil {
+			continue
+		}
+		count++
+		if entry.Row != 0 {
+			logger.Warnf("Row %v pays %v to %v again, it repeats row %v of the batch", offset+i+1, txs[i].Amount, txs[i].To, offset+entry.Row)
+			continue
+		}
+		logger.Warnf("Row %v pays %v to %v again, it was streamed as %v from %v at %v",
+			offset+i+1, txs[i].Amount, txs[i].To, entry.Hash, entry.File, entry.Streamed.Format(time.RFC3339))
+	}
+	if count == 0 {
+		return nil
+	}
+	if allowDuplicatesFlag {
+		logger.Warnf("%v rows were streamed before or repeat a row of the batch, they are streamed because of --allow-duplicates", count)
+		return nil
+	}
+	return fmt.Errorf("%v rows were streamed before or repeat a row of the batch, use --allow-duplicates to stream them", count)
+}
+
+// withSender returns rows where rows without sender are replaced with copies having the address of key
+func withSender(txs domain.TransactionList, key *crypto.PrivateKey) domain.TransactionList {
+	if key == nil {
+		return txs
+	}
+	sender := key.Address().Hex()
+	filled := make(domain.TransactionList, len(txs))
+	for i, tx := range txs {
+		filled[i] = tx
+		if tx.From == "" {
+			copied := *tx
+			copied.From = sender
+			filled[i] = &copied
+		}
+	}
+	return filled
+}
+
+// writeSignedReport writes results report of rows which were signed but not streamed, if the report is set
+func writeSignedReport(reportUC domain.ReportUseCase, txs domain.TransactionList, signedTxs []string) error {
+	if reportFileFlag == "" {
+		return nil
+	}
+	results, err := reportUC.GetResults(txs, signedTxs)
+	if err != nil {
+		return err
+	}
+	return reportUC.WriteResultsToFile(results, reportFileFlag)
+}
+
+func exportTxIDs(uc domain.TransactionListUseCase, txIDs []string, extras []map[string]string, exportFile string) error {
 	var err error
 	if exportFile != "" {
-		err = uc.WriteTxIDsToFile(txIDs, exportFile)
+		err = uc.WriteTxIDsToFile(txIDs, extras, exportFile)
 	} else {
 		err = uc.WriteTxIDsToConsole(txIDs)
 	}
 	return err
 }
 
+func newLogger() logger.Logger {
+	if verbosityFlag > 7 {
+		verbosityFlag = 7
+	}
+	log := zap.NewApiLogger(verbosityFlag)
+	log.InitLogger()
+	return log
+}
+
+// getSigningKey is loading private key from the file chosen by flags, returns nil if no key file is set
+func getSigningKey() (*crypto.PrivateKey, error) {
+	if privateKeyFileFlag != "" && UTCFileFlag != "" {
+		return nil, errors.New("Cannot use both fags for private key and encrypted UTC file")
+	}
+	if privateKeyFileFlag != "" {
+		privateKey, err := getPrivateKey(privateKeyFileFlag)
+		if err != nil {
+			return nil, fmt.Errorf("Error on getting private key from file: %v", err)
+		}
+		return privateKey, nil
+	}
+	if UTCFileFlag != "" {
+		privateKey, err := getPrivateKeyFromUTC(UTCFileFlag, UTCFilePasswordFlag)
+		if err != nil {
+			return nil, fmt.Errorf("Error on getting private key from UTC file: %v", err)
+		}
+		return privateKey, nil
+	}
+	return nil, nil
+}
+
+// checkApprovals refuses a batch without enough approvals when approvers are configured, and any batch when
+// approvals are required but approvers are not configured
+func checkApprovals(uc domain.ApprovalUseCase, signedTxs []string) error {
+	if approversFileFlag == "" {
+		if requireApprovalsFlag {
+			return errors.New("approvals are required, set approvers with flag --approvers-file")
+		}
+		return nil
+	}
+	approvalsFile := getApprovalsFile()
+	if approvalsFile == "" {
+		return errors.New("approvals file is not set, use flag --approvals-file")
+	}
+	approvers, err := uc.GetApproversFromFile(approversFileFlag)
+	if err != nil {
+		return err
+	}
+	approvals, err := uc.GetApprovalsFromFile(approvalsFile)
+	if err != nil {
+		return err
+	}
+	return uc.VerifyApprovals(signedTxs, approvals, approvers, requiredApprovalsFlag)
+}
+
+// getStartNonces parses start nonces of senders from flag
+func getStartNonces() (map[string]uint64, error) {
+	startNonces := map[string]uint64{}
+	for sender, value := range startNonceFlag {
+		nonce, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad start nonce %q of sender %v: %v", value, sender, err)
+		}
+		startNonces[sender] = nonce
+	}
+	return startNonces, nil
+}
+
+// getCSVFormat builds layout of CSV and XLSX input from flags
+func getCSVFormat() (*domain.CSVFormat, error) {
+	delimiter, err := getCSVRune(csvDelimiterFlag)
+	if err != nil {
+		return nil, fmt.Errorf("bad CSV delimiter: %v", err)
+	}
+	comment, err := getCSVRune(csvCommentFlag)
+	if err != nil {
+		return nil, fmt.Errorf("bad CSV comment character: %v", err)
+	}
+	if csvThousandsSeparatorFlag != "" && csvThousandsSeparatorFlag == csvDecimalSeparatorFlag {
+		return nil, errors.New("thousands and decimal separators must differ")
+	}
+	columns := map[string]string{}
+	for field, column := range csvColumnsFlag {
+		columns[strings.ToLower(strings.TrimSpace(field))] = column
+	}
+	return &domain.CSVFormat{
+		Delimiter:          delimiter,
+		Comment:            comment,
+		Titles:             titlesFlag,
+		ThousandsSeparator: csvThousandsSeparatorFlag,
+		DecimalSeparator:   csvDecimalSeparatorFlag,
+		Sheet:              sheetFlag,
+		Columns:            columns,
+	}, nil
+}
+
+// getCSVRune parses a single character flag, tab may be given as 'tab' or '\t'
+func getCSVRune(value string) (rune, error) {
+	switch value {
+	case "":
+		return 0, nil
+	case "tab", `\t`:
+		return '\t', nil
+	}
+	runes := []rune(value)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("%q is not a single character", value)
+	}
+	return runes[0], nil
+}
+
+// getEnergyPriceStrategy builds energy price strategy from flags
+func getEnergyPriceStrategy() (*domain.EnergyPriceStrategy, error) {
+	strategy := &domain.EnergyPriceStrategy{
+		Multiplier:   energyPriceMultiplierFlag,
+		Percentile:   energyPricePercentileFlag,
+		Blocks:       energyPriceBlocksFlag,
+		Wait:         energyPriceWaitFlag,
+		PollInterval: pollIntervalFlag,
+	}
+	if strategy.Multiplier <= 0 {
+		return nil, fmt.Errorf("energy price multiplier must be positive, got %v", strategy.Multiplier)
+	}
+	if strategy.Percentile < 0 || strategy.Percentile > 100 {
+		return nil, fmt.Errorf("energy price percentile must be between 1 and 100, got %v", strategy.Percentile)
+	}
+	if strategy.Percentile > 0 && strategy.Blocks == 0 {
+		return nil, errors.New("energy price percentile needs at least one block, set --energy-price-blocks")
+	}
+	if energyPricePremiumFlag != "" {
+		premium, ok := new(big.Int).SetString(energyPricePremiumFlag, 10)
+		if !ok {
+			return nil, fmt.Errorf("bad energy price premium %q", energyPricePremiumFlag)
+		}
+		strategy.Premium = premium
+	}
+	if maxEnergyPriceFlag != "" {
+		maxPrice, ok := new(big.Int).SetString(maxEnergyPriceFlag, 10)
+		if !ok {
+			return nil, fmt.Errorf("bad max energy price %q", maxEnergyPriceFlag)
+		}
+		strategy.MaxPrice = maxPrice
+	}
+	return strategy, nil
+}
+
+// getApprovalsFile returns approvals file from flag or the one stored next to the stream file
+func getApprovalsFile() string {
+	// standard input has no name to put approvals next to
+	if approvalsFileFlag != "" || signedTxFileFlag == "" || signedTxFileFlag == pkg.Stdio {
+		return approvalsFileFlag
+	}
+	return signedTxFileFlag + ".approvals.json"
+}
+
+// getPoolKeys loads keys of the sender pool by their addresses
+func getPoolKeys() (map[string]*crypto.PrivateKey, error) {
+	keys := map[string]*crypto.PrivateKey{}
+	for _, fileName := range poolKeysFlag {
+		key, err := getKeyFromFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("Error on getting pool key from file %v: %v", fileName, err)
+		}
+		keys[key.Address().Hex()] = key
+	}
+	return keys, nil
+}
+
+// poolSenders returns addresses of the pool keys in stable order
+func poolSenders(keys map[string]*crypto.PrivateKey) []string {
+	senders := make([]string, 0, len(keys))
+	for sender := range keys {
+		senders = append(senders, sender)
+	}
+	sort.Strings(senders)
+	return senders
+}
+
+// getKeyFromFile loads UTC file if the file is JSON and hex private key otherwise
+func getKeyFromFile(fileName string) (*crypto.PrivateKey, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	if json.Valid(data) {
+		return getPrivateKeyFromUTC(fileName, UTCFilePasswordFlag)
+	}
+	return getPrivateKey(fileName)
+}
+
 func getPrivateKey(fileName string) (*crypto.PrivateKey, error) {
 	data, err := os.ReadFile(fileName)
 	if err != nil {
@@ -173,7 +995,7 @@ func getPrivateKeyFromUTC(UTCFileName, UTCPasswordFileName string) (*crypto.Priv
 	}
 
 	if UTCPasswordFileName == "" {
-		fmt.Print("Enter password for UTC file: \n")
+		fmt.Fprint(os.Stderr, "Enter password for UTC file: \n")
 		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
 		if err != nil {
 			return nil, err
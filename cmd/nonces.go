@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/core-coin/go-core/v2/common"
+
+	"github.com/core-coin/pigeon/infrastructure/rpcClient/gocore"
+	nonceuc "github.com/core-coin/pigeon/nonce/usecase"
+	reportuc "github.com/core-coin/pigeon/report/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// noncesCmd reports nonce gaps of senders and fills them
+var noncesCmd = &cobra.Command{
+	Use:   "nonces [address...]",
+	Short: "Detect and repair nonce gaps",
+	Long:  `This command compares latest and pending nonces of senders with the node's transaction pool, reports gaps before queued transactions and optionally fills them with zero-value self-sends`,
+	Run: func(cmd *cobra.Command, args []string) {
+		nonces(args)
+	},
+}
+
+var fillGapsFlag bool
+
+func init() {
+	noncesCmd.Flags().BoolVar(&fillGapsFlag, "fill", false, "Fill nonce gaps of the key owner with zero-value self-sends")
+	RootCmd.AddCommand(noncesCmd)
+}
+
+func nonces(args []string) {
+	logger := newLogger()
+
+	common.DefaultNetworkID = common.NetworkID(networkIDFlag)
+
+	privateKey, err := getSigningKey()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if fillGapsFlag && privateKey == nil {
+		logger.Fatal("Sender key is required to fill gaps, use flag --utc-file or --private-key-file")
+	}
+
+	var addresses []string
+	for _, arg := range args {
+		address, err := common.HexToAddress(arg)
+		if err != nil {
+			logger.Fatalf("Bad address %v: %v", arg, err)
+		}
+		addresses = append(addresses, address.Hex())
+	}
+	if privateKey != nil {
+		addresses = append(addresses, privateKey.Address().Hex())
+	}
+	if len(addresses) == 0 {
+		logger.Fatal("Pass addresses to inspect or sender key")
+	}
+
+	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
+	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger, nil, nil, nil, nil)
+	reportUC := reportuc.NewReportUsecase(pollIntervalFlag, rpcClient, logger)
+	nonceUC := nonceuc.NewNonceUsecase(rpcClient, logger)
+
+	reports, err := nonceUC.InspectNonces(addresses)
+	if err != nil {
+		logger.Fatalf("Error on inspecting nonces: %v", err)
+	}
+	for _, report := range reports {
+		logger.Infof("%v: latest nonce %v, pending nonce %v, in flight %v", report.Address, report.Latest, report.Pending, report.Pending-report.Latest)
+		if len(report.Queued) > 0 {
+			logger.Warnf("%v: queued nonces %v", report.Address, report.Queued)
+		}
+		if len(report.Gaps) > 0 {
+			logger.Warnf("%v: missing nonces %v", report.Address, report.Gaps)
+		}
+	}
+
+	if !fillGapsFlag {
+		return
+	}
+	// the key owner is always the last inspected address
+	txList, err := nonceUC.GetGapFillingTxs(reports[len(reports)-1])
+	if err != nil {
+		logger.Fatalf("Error on building gap filling transactions: %v", err)
+	}
+	if len(txList) == 0 {
+		logger.Info("There are no nonce gaps to fill")
+		return
+	}
+	err = confirmBatch(uc, txList, "fill gaps with")
+	if err != nil {
+		logger.Fatal(err)
+	}
+	signedTxs, err := uc.SignTxs(txList, privateKey)
+	if err != nil {
+		logger.Fatalf("Error on signing gap filling transactions: %v", err)
+	}
+	if dryrunFlag {
+		logger.Info("Transactions were not streamed because of dry run!")
+		return
+	}
+	_, err = streamAndExport(logger, uc, reportUC, nil, txList, signedTxs)
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
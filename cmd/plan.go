@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/core-coin/pigeon/domain"
+	planuc "github.com/core-coin/pigeon/plan/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// planCmd splits a total across weighted recipients into a transaction file
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Split a total across recipients by weights",
+	Long:  `This command writes a file with transactions paying a total amount to recipients in proportion to their weights`,
+	Run: func(cmd *cobra.Command, args []string) {
+		plan()
+	},
+}
+
+var (
+	planTotalFlag       string
+	planRecipientsFlag  string
+	planFromFlag        string
+	planRemainderToFlag string
+	planMinPayoutFlag   string
+	planMaxPayoutFlag   string
+)
+
+func init() {
+	planCmd.Flags().StringVar(&planTotalFlag, "total", "", "Total amount in Core to distribute")
+	planCmd.Flags().StringVar(&planRecipientsFlag, "recipients", "", "CSV or JSON file with recipient addresses and weights")
+	planCmd.Flags().StringVar(&planFromFlag, "from", "", "Sender of planned transactions (default is left empty)")
+	planCmd.Flags().StringVar(&planRemainderToFlag, "remainder-to", "", "Recipient getting the rounding remainder (default is the first recipient)")
+	planCmd.Flags().StringVar(&planMinPayoutFlag, "min-payout", "", "Recipients whose share in Core is below this get nothing")
+	planCmd.Flags().StringVar(&planMaxPayoutFlag, "max-payout", "", "Cap of a payout in Core, the excess goes to other recipients")
+	RootCmd.AddCommand(planCmd)
+}
+
+func plan() {
+	logger := newLogger()
+
+	if planRecipientsFlag == "" {
+		logger.Fatal("File with recipients is not set, use flag --recipients")
+	}
+	if exportTxFileFlag == "" {
+		logger.Fatal("Output file is not set, use flag --output")
+	}
+	total, err := domain.ParseAmount(planTotalFlag)
+	if err != nil {
+		logger.Fatalf("Bad total %q, use flag --total: %v", planTotalFlag, err)
+	}
+	options := &domain.PlanOptions{
+		From:        planFromFlag,
+		RemainderTo: planRemainderToFlag,
+	}
+	options.MinPayout, err = domain.ParseAmount(planMinPayoutFlag)
+	if err != nil {
+		logger.Fatalf("Bad minimum payout %q: %v", planMinPayoutFlag, err)
+	}
+	options.MaxPayout, err = domain.ParseAmount(planMaxPayoutFlag)
+	if err != nil {
+		logger.Fatalf("Bad maximum payout %q: %v", planMaxPayoutFlag, err)
+	}
+
+	uc := txlistuc.NewTransactionListUsecase(nil, logger, nil, nil, nil, nil)
+	planUC := planuc.NewPlanUsecase(logger)
+
+	recipients, err := planUC.GetRecipientsFromFile(planRecipientsFlag)
+	if err != nil {
+		logger.Fatalf("Error on getting recipients from file: %v", err)
+	}
+	txList, err := planUC.Plan(total, recipients, options)
+	if err != nil {
+		logger.Fatalf("Error on planning distribution: %v", err)
+	}
+	err = uc.WriteTxsToFile(txList, exportTxFileFlag)
+	if err != nil {
+		logger.Fatalf("Error on writing transactions to file: %v", err)
+	}
+	logger.Infof("Successfully planned %v transactions paying %v Core into a file %v", len(txList), total, exportTxFileFlag)
+}
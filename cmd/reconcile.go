@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/core-coin/go-core/v2/common"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient/gocore"
+	reconcileuc "github.com/core-coin/pigeon/reconcile/usecase"
+	reportuc "github.com/core-coin/pigeon/report/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// reconcileCmd checks intended payments against the chain
+var reconcileCmd = &cobra.Command{
+	Use:   "reconcile",
+	Short: "Check that payments of a file were mined",
+	Long:  `This command looks up transactions of a tx IDs file or results report and their receipts, and checks that every payment of the transaction file was mined once with its recipient, value and sender. It exits with error on missing, failed, duplicated or unexpected transactions`,
+	Run: func(cmd *cobra.Command, args []string) {
+		reconcile()
+	},
+}
+
+var journalFileFlag string
+
+func init() {
+	reconcileCmd.Flags().StringVar(&journalFileFlag, "journal", "", "Results report of the run (written by --report) instead of tx IDs file")
+	RootCmd.AddCommand(reconcileCmd)
+}
+
+func reconcile() {
+	logger := newLogger()
+
+	common.DefaultNetworkID = common.NetworkID(networkIDFlag)
+
+	if txFileFlag == "" {
+		logger.Fatal("File with transactions is not set, use flag --file")
+	}
+	if (signedTxResultFileFlag == "") == (journalFileFlag == "") {
+		logger.Fatal("Set either file with transaction IDs (--tx-ids-file) or results report (--journal)")
+	}
+	csvFormat, err := getCSVFormat()
+	if err != nil {
+		logger.Fatal(err)
+	}
+
+	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
+	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger, nil, nil, nil, nil)
+	reportUC := reportuc.NewReportUsecase(pollIntervalFlag, rpcClient, logger)
+	reconcileUC := reconcileuc.NewReconcileUsecase(rpcClient, logger)
+
+	txList, err := uc.ReadTxsFromFile(txFileFlag, csvFormat)
+	if err != nil {
+		logger.Fatalf("Error on getting transactions from file: %v", err)
+	}
+	var txIDs []string
+	if journalFileFlag != "" {
+		results, err := reportUC.GetResultsFromFile(journalFileFlag)
+		if err != nil {
+			logger.Fatalf("Error on getting results report from file: %v", err)
+		}
+		txIDs = fillFromJournal(txList, results)
+	} else {
+		txIDs, _, err = uc.GetTxIDsFromFile(signedTxResultFileFlag)
+		if err != nil {
+			logger.Fatalf("Error on getting transaction IDs from file: %v", err)
+		}
+	}
+
+	discrepancies, err := reconcileUC.Reconcile(txList, txIDs)
+	if err != nil {
+		logger.Fatalf("Error on reconciling transactions: %v", err)
+	}
+	for _, d := range discrepancies {
+		var place []string
+		if d.Row != 0 {
+			place = append(place, fmt.Sprintf("row %v", d.Row))
+		}
+		if d.Hash != "" {
+			place = append(place, d.Hash)
+		}
+		logger.Errorf("%v %v: %v", strings.Join(place, " "), d.Kind, d.Detail)
+	}
+	if len(discrepancies) > 0 {
+		logger.Fatalf("Found %v discrepancies between %v payments and the chain", len(discrepancies), len(txList))
+	}
+	logger.Infof("All %v payments were mined as intended", len(txList))
+}
+
+// fillFromJournal returns hashes of results report, rows of the transaction file get sender and nonce
+// of their results if the report has a result for every row
+func fillFromJournal(txs domain.TransactionList, results []*domain.Result) []string {
+	var txIDs []string
+	for _, result := range results {
+		if result.Hash != "" {
+			txIDs = append(txIDs, result.Hash)
+		}
+	}
+	if len(results) != len(txs) {
+		return txIDs
+	}
+	for i, tx := range txs {
+		if tx.From == "" {
+			tx.From = results[i].Transaction.From
+		}
+		if tx.Nonce == "" {
+			tx.Nonce = results[i].Transaction.Nonce
+		}
+	}
+	return txIDs
+}
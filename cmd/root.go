@@ -3,8 +3,13 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
 )
 
 // Flags
@@ -17,6 +22,7 @@ var (
 
 	titlesFlag    bool
 	dryrunFlag    bool
+	yesFlag       bool
 	verbosityFlag int
 
 	networkIDFlag       int
@@ -25,6 +31,52 @@ var (
 	UTCFilePasswordFlag string
 
 	gocoreAddressFlag string
+
+	approvalsFileFlag     string
+	approversFileFlag     string
+	requiredApprovalsFlag int
+	requireApprovalsFlag  bool
+
+	policyFileFlag string
+
+	startNonceFlag          map[string]string
+	nonceDirFlag            string
+	nonceReservationTTLFlag time.Duration
+
+	energyPriceMultiplierFlag float64
+	energyPricePremiumFlag    string
+	maxEnergyPriceFlag        string
+	energyPricePercentileFlag int
+	energyPriceBlocksFlag     uint64
+	energyPriceWaitFlag       time.Duration
+	pollIntervalFlag          time.Duration
+
+	streamBelowEnergyPriceFlag string
+	streamDeadlineFlag         string
+	notBeforeFlag              string
+	notBeforeTimeoutFlag       time.Duration
+
+	poolKeysFlag []string
+	parallelFlag bool
+
+	maxInFlightFlag uint64
+
+	csvDelimiterFlag          string
+	csvCommentFlag            string
+	csvColumnsFlag            map[string]string
+	csvThousandsSeparatorFlag string
+	csvDecimalSeparatorFlag   string
+	sheetFlag                 string
+
+	formatFlag       string
+	outputFormatFlag string
+
+	reportFileFlag string
+	trackFlag      time.Duration
+
+	ledgerFileFlag      string
+	idempotencyKeyFlag  []string
+	allowDuplicatesFlag bool
 )
 
 // RootCmd represents the base command when called without any subcommands
@@ -33,11 +85,35 @@ var RootCmd = &cobra.Command{
 	Example: examples,
 	Short:   "Sign & transmit transactions",
 	Long:    `This application is used to sign transactions and stream them in Core Blockchain`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		err := applyConfig(cmd)
+		if err != nil {
+			return err
+		}
+		return setStdioFormats()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		execute()
 	},
 }
 
+// setStdioFormats checks format flags and applies them to standard input and output
+func setStdioFormats() error {
+	inputs := map[string]bool{"json": true, "jsonl": true, "ndjson": true, "csv": true, "xlsx": true}
+	outputs := map[string]bool{"json": true, "jsonl": true, "ndjson": true, "csv": true}
+	format := strings.ToLower(strings.TrimPrefix(formatFlag, "."))
+	if !inputs[format] {
+		return fmt.Errorf("unsupported input format %q", formatFlag)
+	}
+	outputFormat := strings.ToLower(strings.TrimPrefix(outputFormatFlag, "."))
+	if !outputs[outputFormat] {
+		return fmt.Errorf("unsupported output format %q", outputFormatFlag)
+	}
+	pkg.InputFormat = "." + format
+	pkg.OutputFormat = "." + outputFormat
+	return nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -50,7 +126,8 @@ func Execute() {
 func init() {
 
 	RootCmd.PersistentFlags().BoolVarP(&dryrunFlag, "dry-run", "d", false, "Test the schema (do not stream, do not sign)")
-	RootCmd.PersistentFlags().BoolVarP(&titlesFlag, "titles", "t", false, "Skip 1 line (for CSV)")
+	RootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "Do not ask for confirmation before signing and streaming")
+	RootCmd.PersistentFlags().BoolVarP(&titlesFlag, "titles", "t", false, "First line of CSV is titles (detected without the flag when it names the to column)")
 	RootCmd.PersistentFlags().IntVarP(&verbosityFlag, "verbosity ", "v", 2, "Verbosity (from 1 to 7)")
 
 	RootCmd.PersistentFlags().IntVarP(&networkIDFlag, "network", "n", 1, "Network to stream on")
@@ -59,12 +136,51 @@ func init() {
 	RootCmd.PersistentFlags().StringVarP(&UTCFileFlag, "utc-file", "u", "", "UTC file with encoded private key")
 	RootCmd.PersistentFlags().StringVarP(&UTCFilePasswordFlag, "password-file", "p", "", "File with password to for file")
 
-	RootCmd.PersistentFlags().StringVarP(&txFileFlag, "file", "f", "", "Input file with transactions")
+	RootCmd.PersistentFlags().StringVarP(&txFileFlag, "file", "f", "", "Input file with transactions, or directory or glob of files signed one by one")
 	RootCmd.PersistentFlags().StringVarP(&exportTxFileFlag, "output", "o", "", "Output file with signed transactions")
 
 	RootCmd.PersistentFlags().StringVarP(&signedTxFileFlag, "stream-file", "s", "", "File for streaming transactions into blockchain")
 	RootCmd.PersistentFlags().StringVarP(&signedTxResultFileFlag, "tx-ids-file", "i", "", "File where to store streamed tx IDs")
 
+	RootCmd.PersistentFlags().StringToStringVar(&startNonceFlag, "start-nonce", nil, "First nonce of a sender for rows without nonce, e.g. cb...=12")
+	RootCmd.PersistentFlags().StringVar(&nonceDirFlag, "nonce-dir", "", "Directory with nonce reservations shared by pigeon runs on this host")
+	RootCmd.PersistentFlags().DurationVar(&nonceReservationTTLFlag, "nonce-reservation-ttl", time.Hour, "Time after which reserved nonces of batches which were neither saved nor streamed are given up")
+	RootCmd.PersistentFlags().Float64Var(&energyPriceMultiplierFlag, "energy-price-multiplier", 1, "Multiplier of network energy price for transactions without price")
+	RootCmd.PersistentFlags().StringVar(&energyPricePremiumFlag, "energy-price-premium", "", "Premium in ore added to network energy price for transactions without price")
+	RootCmd.PersistentFlags().StringVar(&maxEnergyPriceFlag, "max-energy-price", "", "Cap of energy price in ore for transactions without price")
+	RootCmd.PersistentFlags().IntVar(&energyPricePercentileFlag, "energy-price-percentile", 0, "Use percentile (1-100) of energy prices paid in recent blocks as network price")
+	RootCmd.PersistentFlags().Uint64Var(&energyPriceBlocksFlag, "energy-price-blocks", 20, "Number of recent blocks for energy price percentile")
+	RootCmd.PersistentFlags().DurationVar(&energyPriceWaitFlag, "energy-price-wait", 0, "How long to wait for energy price to drop below the cap (default is to refuse at once)")
+	RootCmd.PersistentFlags().DurationVar(&pollIntervalFlag, "poll-interval", 15*time.Second, "Interval of polling the node while waiting")
+	RootCmd.PersistentFlags().StringVar(&streamBelowEnergyPriceFlag, "stream-below-energy-price", "", "Hold streaming until network energy price in ore drops to this value")
+	RootCmd.PersistentFlags().StringVar(&streamDeadlineFlag, "stream-deadline", "", "Stream anyway after this time (RFC3339 or duration from now)")
+	RootCmd.PersistentFlags().StringVar(&notBeforeFlag, "not-before", "", "Do not stream before this time (RFC3339 or duration from now) or block number")
+	RootCmd.PersistentFlags().DurationVar(&notBeforeTimeoutFlag, "not-before-timeout", 0, "Give up if the --not-before block is not reached in this time (default is to wait without limit)")
+	RootCmd.PersistentFlags().StringSliceVar(&poolKeysFlag, "pool-keys", nil, "Private key or UTC files of the sender pool, rows must not have sender")
+	RootCmd.PersistentFlags().BoolVar(&parallelFlag, "parallel", false, "Stream transactions of different senders in parallel")
+	RootCmd.PersistentFlags().Uint64Var(&maxInFlightFlag, "max-in-flight", 0, "Maximum number of pending transactions per sender while streaming (default is no limit)")
+	RootCmd.PersistentFlags().StringVar(&csvDelimiterFlag, "csv-delimiter", ",", "Delimiter of CSV fields, e.g. ';' or 'tab'")
+	RootCmd.PersistentFlags().StringVar(&csvCommentFlag, "csv-comment", "", "Character starting comment lines in CSV, e.g. '#'")
+	RootCmd.PersistentFlags().StringToStringVar(&csvColumnsFlag, "csv-columns", nil, "Source CSV columns (title or 1-based number) of fields, e.g. to=IBAN,amount=3")
+	RootCmd.PersistentFlags().StringVar(&csvThousandsSeparatorFlag, "thousands-separator", "", "Thousands separator removed from numbers in CSV, e.g. ',' or '.'")
+	RootCmd.PersistentFlags().StringVar(&csvDecimalSeparatorFlag, "decimal-separator", ".", "Decimal separator of numbers in CSV")
+	RootCmd.PersistentFlags().StringVar(&configFileFlag, "config", "", "YAML or TOML file with default values of flags (default is pigeon.yaml, pigeon.yml or pigeon.toml in user config directory)")
+	RootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile of config file to apply, e.g. mainnet")
+	RootCmd.PersistentFlags().StringVar(&formatFlag, "format", "json", "Format of data read from standard input given as '-' (json, jsonl, ndjson, csv, xlsx)")
+	RootCmd.PersistentFlags().StringVar(&outputFormatFlag, "output-format", "json", "Format of data written to standard output given as '-' (json, jsonl, ndjson, csv)")
+	RootCmd.PersistentFlags().StringVar(&sheetFlag, "sheet", "", "Name or 1-based number of XLSX sheet with transactions (default is the first sheet)")
+	RootCmd.PersistentFlags().StringVar(&reportFileFlag, "report", "", "CSV, JSON or JSON lines file with outcome of every input row")
+	RootCmd.PersistentFlags().DurationVar(&trackFlag, "track", 0, "How long to wait for streamed transactions to be mined, adds block number, energy used and fee to report")
+	RootCmd.PersistentFlags().StringVar(&ledgerFileFlag, "ledger", "", "File with ledger of streamed payments (default is ledger.jsonl in user config directory)")
+	RootCmd.PersistentFlags().StringSliceVar(&idempotencyKeyFlag, "idempotency-key", domain.DefaultIdempotencyKey, "Fields and extra columns identifying a payment in the ledger")
+	RootCmd.PersistentFlags().BoolVar(&allowDuplicatesFlag, "allow-duplicates", false, "Stream rows found in the ledger again, only warning about them")
+	RootCmd.PersistentFlags().StringVar(&policyFileFlag, "policy-file", "", "File with spending policy enforced on signing")
+
+	RootCmd.PersistentFlags().StringVar(&approvalsFileFlag, "approvals-file", "", "File with approvals of the batch (default is stream file + .approvals.json)")
+	RootCmd.PersistentFlags().StringVar(&approversFileFlag, "approvers-file", "", "File with approver public keys, streaming requires approvals if set")
+	RootCmd.PersistentFlags().IntVar(&requiredApprovalsFlag, "required-approvals", 1, "Number of valid approvals required to stream a batch")
+	RootCmd.PersistentFlags().BoolVar(&requireApprovalsFlag, "require-approvals", false, "Refuse to stream without --approvers-file, set it in config file to make approvals mandatory")
+
 }
 
 const examples = `
@@ -73,4 +189,6 @@ To sign and stream transactions: pigeon -f {path to file with transactions} -u {
 To sign and stream transactions(+ save streamed transaction IDs to file): pigeon -f {path to file with transactions} -u {path to UTC file} -i {path to file where to save transactions hashes}
 To stream signed transactions: pigeon -s {path to file with signed transactions}
 To stream signed transactions(+ save streamed transaction IDs to file): pigeon -s {path to file with signed transactions} -i {path to file where to save transactions hashes}
+To approve signed transactions: pigeon approve -s {path to file with signed transactions} -u {path to approver UTC file}
+To stream approved transactions: pigeon -s {path to file with signed transactions} --approvers-file {path to file with approver public keys} --required-approvals {N}
 `
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/core-coin/go-core/v2/common"
+
+	energypriceuc "github.com/core-coin/pigeon/energy_price/usecase"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient/gocore"
+	reportuc "github.com/core-coin/pigeon/report/usecase"
+	sweepuc "github.com/core-coin/pigeon/sweep/usecase"
+	txlistuc "github.com/core-coin/pigeon/transaction_list/usecase"
+)
+
+// sweepCmd moves whole balances of senders to one address
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Move entire balances minus fees",
+	Long:  `This command transfers the whole balance of every source key minus the fee to the destination address`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sweep()
+	},
+}
+
+var sweepToFlag string
+
+func init() {
+	sweepCmd.Flags().StringVar(&sweepToFlag, "to", "", "Destination address")
+	RootCmd.AddCommand(sweepCmd)
+}
+
+func sweep() {
+	logger := newLogger()
+
+	common.DefaultNetworkID = common.NetworkID(networkIDFlag)
+
+	to, err := common.HexToAddress(sweepToFlag)
+	if err != nil {
+		logger.Fatalf("Bad destination address %q, use flag --to: %v", sweepToFlag, err)
+	}
+	keys, err := getPoolKeys()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	privateKey, err := getSigningKey()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if privateKey != nil {
+		keys[privateKey.Address().Hex()] = privateKey
+	}
+	if len(keys) == 0 {
+		logger.Fatal("Source keys are not set, use flags --utc-file, --private-key-file or --pool-keys")
+	}
+
+	rpcClient := gocore.NewRPCClient(gocoreAddressFlag, time.Second*5)
+	strategy, err := getEnergyPriceStrategy()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	priceUC := energypriceuc.NewEnergyPriceUsecase(strategy, rpcClient, logger)
+	uc := txlistuc.NewTransactionListUsecase(rpcClient, logger, nil, nil, priceUC, nil)
+	reportUC := reportuc.NewReportUsecase(pollIntervalFlag, rpcClient, logger)
+	sweepUC := sweepuc.NewSweepUsecase(rpcClient, priceUC, logger)
+
+	txList, err := sweepUC.GetSweepTxs(poolSenders(keys), to.Hex())
+	if err != nil {
+		logger.Fatalf("Error on getting balances: %v", err)
+	}
+	if len(txList) == 0 {
+		logger.Info("There is nothing to sweep")
+		return
+	}
+	txList, err = uc.FillTxs(txList, nil)
+	if err != nil {
+		logger.Fatalf("Error on getting nonces: %v", err)
+	}
+	err = confirmBatch(uc, txList, "sweep with")
+	if err != nil {
+		logger.Fatal(err)
+	}
+	signedTxs, err := uc.SignTxsWithKeys(txList, keys)
+	if err != nil {
+		logger.Fatalf("Error on signing sweep transactions: %v", err)
+	}
+	if exportTxFileFlag != "" {
+		err = uc.WriteSignedTxsToFile(signedTxs, nil, exportTxFileFlag)
+		if err != nil {
+			logger.Fatalf("Error on writing signed transactions to file: %v", err)
+		}
+		logger.Infof("Successfully saved signed transactions into a file %v", exportTxFileFlag)
+		return
+	}
+	if dryrunFlag {
+		logger.Info("Transactions were not streamed because of dry run!")
+		return
+	}
+	_, err = streamAndExport(logger, uc, reportUC, nil, txList, signedTxs)
+	if err != nil {
+		logger.Fatal(err)
+	}
+}
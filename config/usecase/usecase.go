@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/core-coin/pigeon/domain"
+)
+
+// configNames are looked up in the user config directory when no config file is given
+var configNames = []string{"pigeon.yaml", "pigeon.yml", "pigeon.toml"}
+
+// FindConfigFile returns the first existing default config file, empty string if there is none
+func FindConfigFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", nil
+	}
+	for _, name := range configNames {
+		fileName := filepath.Join(dir, "pigeon", name)
+		_, err := os.Stat(fileName)
+		if err == nil {
+			return fileName, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// GetConfigFromFile is loading config from YAML or TOML file, chosen by extension.
+// Lists are joined with commas and maps are written as key=value pairs, like flags take them.
+// YAML values are taken as written, so large numbers keep all their digits, integral floats are written out.
+func GetConfigFromFile(fileName string) (*domain.Config, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		raw, err = readYAML(data)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, errors.New("unsupported config file extension, use .yaml, .yml or .toml")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config := &domain.Config{Profiles: map[string]map[string]string{}}
+	if profile, ok := raw["profile"]; ok {
+		config.Profile = fmt.Sprint(profile)
+		delete(raw, "profile")
+	}
+	if profiles, ok := raw["profiles"]; ok {
+		profileMap, ok := profiles.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("profiles must be a map of profile names to settings")
+		}
+		for name, settings := range profileMap {
+			settingsMap, ok := settings.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("profile %v must be a map of settings", name)
+			}
+			config.Profiles[name], err = flagValues(settingsMap)
+			if err != nil {
+				return nil, fmt.Errorf("profile %v: %v", name, err)
+			}
+		}
+		delete(raw, "profiles")
+	}
+	config.Settings, err = flagValues(raw)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// flagValues is writing settings the way they are given on command line
+func flagValues(settings map[string]interface{}) (map[string]string, error) {
+	values := map[string]string{}
+	for name, value := range settings {
+		switch v := value.(type) {
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, formatValue(item))
+			}
+			values[name] = strings.Join(items, ",")
+		case map[string]interface{}:
+			pairs := make([]string, 0, len(v))
+			for key, item := range v {
+				pairs = append(pairs, key+"="+formatValue(item))
+			}
+			sort.Strings(pairs)
+			values[name] = strings.Join(pairs, ",")
+		case nil:
+			return nil, fmt.Errorf("%v has no value", name)
+		default:
+			values[name] = formatValue(v)
+		}
+	}
+	return values, nil
+}
+
+// formatValue is writing floats without exponent, e.g. 1e+20 as 100000000000000000000
+func formatValue(value interface{}) string {
+	if v, ok := value.(float64); ok {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// readYAML is reading YAML mappings and sequences with scalars as written, not converted to numbers
+func readYAML(data []byte) (map[string]interface{}, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return map[string]interface{}{}, nil
+	}
+	value, err := yamlValue(document.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("config must be a map of settings")
+	}
+	return raw, nil
+}
+
+// yamlValue is converting a YAML node, null scalars are nil
+func yamlValue(node *yaml.Node) (interface{}, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		values := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := yamlValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			values[node.Content[i].Value] = value
+		}
+		return values, nil
+	case yaml.SequenceNode:
+		values := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := yamlValue(item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			return nil, nil
+		case "!!float":
+			// integral floats like 1e20 are written out, flags of big integers do not take exponents
+			if value, ok := new(big.Rat).SetString(node.Value); ok && value.IsInt() {
+				return value.Num().String(), nil
+			}
+		}
+		return node.Value, nil
+	}
+	return nil, fmt.Errorf("unsupported YAML value in line %v", node.Line)
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGetConfigFromFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		file       string
+		content    string
+		settings   map[string]string
+		profiles   map[string]map[string]string
+		errMessage string
+	}{
+		{
+			name:     "yaml values",
+			file:     "pigeon.yaml",
+			content:  "gocore: http://127.0.0.1:8545\nnetwork: 3\ndry-run: true\npool-keys: [a.key, b.key]\nstart-nonce:\n  cb01: 5\n",
+			settings: map[string]string{"gocore": "http://127.0.0.1:8545", "network": "3", "dry-run": "true", "pool-keys": "a.key,b.key", "start-nonce": "cb01=5"},
+		},
+		{
+			name:     "yaml numbers above 2^53 keep their digits",
+			file:     "pigeon.yml",
+			content:  "max-energy-price: 123456789012345678901234\nenergy-price-premium: 9007199254740993\nenergy-price-multiplier: 1e20\n",
+			settings: map[string]string{"max-energy-price": "123456789012345678901234", "energy-price-premium": "9007199254740993", "energy-price-multiplier": "100000000000000000000"},
+		},
+		{
+			name:     "yaml profiles",
+			file:     "pigeon.yaml",
+			content:  "network: 1\nprofiles:\n  devin:\n    network: 3\n",
+			settings: map[string]string{"network": "1"},
+			profiles: map[string]map[string]string{"devin": {"network": "3"}},
+		},
+		{
+			name:     "yaml floats",
+			file:     "pigeon.yaml",
+			content:  "energy-price-multiplier: 1.25\nmax-energy-price: 2.5e3\n",
+			settings: map[string]string{"energy-price-multiplier": "1.25", "max-energy-price": "2500"},
+		},
+		{
+			name:       "yaml without value",
+			file:       "pigeon.yaml",
+			content:    "gocore:\n",
+			errMessage: "gocore has no value",
+		},
+		{
+			name:     "toml numbers",
+			file:     "pigeon.toml",
+			content:  "max-energy-price = 1e20\nenergy-price-premium = 9007199254740993\nenergy-price-multiplier = 1.25\n",
+			settings: map[string]string{"max-energy-price": "100000000000000000000", "energy-price-premium": "9007199254740993", "energy-price-multiplier": "1.25"},
+		},
+		{
+			name:     "toml lists of numbers",
+			file:     "pigeon.toml",
+			content:  "idempotency-key = [\"to\", \"amount\"]\n[start-nonce]\ncb01 = 2e16\n",
+			settings: map[string]string{"idempotency-key": "to,amount", "start-nonce": "cb01=20000000000000000"},
+		},
+		{
+			name:       "unsupported extension",
+			file:       "pigeon.json",
+			content:    "{}",
+			errMessage: "unsupported config file extension",
+		},
+	}
+
+	dir := t.TempDir()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := filepath.Join(dir, test.file)
+			err := os.WriteFile(fileName, []byte(test.content), 0600)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config, err := GetConfigFromFile(fileName)
+			if test.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errMessage) {
+					t.Fatalf("expected error with %q, got %v", test.errMessage, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkSettings(t, config.Settings, test.settings)
+			for name, settings := range test.profiles {
+				checkSettings(t, config.Profiles[name], settings)
+			}
+		})
+	}
+}
+
+func checkSettings(t *testing.T, settings, expected map[string]string) {
+	t.Helper()
+	if len(settings) != len(expected) {
+		t.Errorf("expected %v settings, got %v", expected, settings)
+	}
+	for name, value := range expected {
+		if settings[name] != value {
+			t.Errorf("expected %v to be %q, got %q", name, value, settings[name])
+		}
+	}
+}
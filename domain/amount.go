@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// Amount is an exact amount of cores kept in ore.
+// It is written as a decimal number of cores in JSON and CSV.
+type Amount struct {
+	ore *big.Int
+}
+
+// NewAmount creates amount from ore
+func NewAmount(ore *big.Int) Amount {
+	return Amount{ore: new(big.Int).Set(ore)}
+}
+
+// ParseAmount parses non-negative decimal amount of cores, exponent notation is allowed if the result fits into ore
+func ParseAmount(value string) (Amount, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Amount{}, nil
+	}
+	// big.Rat also reads fractions like 1/3 and prefixes like 0x, they are not decimal amounts
+	if strings.Trim(value, "0123456789+-.eE") != "" {
+		return Amount{}, fmt.Errorf("bad amount %q", value)
+	}
+	cores, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Amount{}, fmt.Errorf("bad amount %q", value)
+	}
+	if cores.Sign() < 0 {
+		return Amount{}, fmt.Errorf("amount %q is negative", value)
+	}
+	ore := cores.Mul(cores, new(big.Rat).SetInt(pkg.Core))
+	if !ore.IsInt() {
+		return Amount{}, fmt.Errorf("amount %q has more than 18 decimals", value)
+	}
+	return Amount{ore: ore.Num()}, nil
+}
+
+// Ore returns a copy of amount in ore
+func (a Amount) Ore() *big.Int {
+	if a.ore == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.ore)
+}
+
+// Cmp compares amounts like big.Int.Cmp
+func (a Amount) Cmp(b Amount) int {
+	return a.Ore().Cmp(b.Ore())
+}
+
+// Sign returns -1, 0 or 1 like big.Int.Sign
+func (a Amount) Sign() int {
+	return a.Ore().Sign()
+}
+
+// String formats amount as decimal number of cores
+func (a Amount) String() string {
+	return pkg.FormatOre(a.Ore())
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(bytes.TrimSpace(data), `"`)
+	if string(data) == "null" {
+		*a = Amount{}
+		return nil
+	}
+	if len(data) == 0 {
+		return errors.New("empty amount")
+	}
+	amount, err := ParseAmount(string(data))
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
+
+func (a Amount) MarshalCSV() (string, error) {
+	return a.String(), nil
+}
+
+func (a *Amount) UnmarshalCSV(value string) error {
+	amount, err := ParseAmount(value)
+	if err != nil {
+		return err
+	}
+	*a = amount
+	return nil
+}
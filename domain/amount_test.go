@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value      string
+		ore        string
+		errMessage string
+	}{
+		{value: "", ore: "0"},
+		{value: "0", ore: "0"},
+		{value: "-0", ore: "0"},
+		{value: "1", ore: "1000000000000000000"},
+		{value: " 2 ", ore: "2000000000000000000"},
+		{value: "+3", ore: "3000000000000000000"},
+		{value: "1.5", ore: "1500000000000000000"},
+		{value: ".25", ore: "250000000000000000"},
+		{value: "0.000000000000000001", ore: "1"},
+		{value: "123456789.123456789123456789", ore: "123456789123456789123456789"},
+		{value: "1e3", ore: "1000000000000000000000"},
+		{value: "1.5E-3", ore: "1500000000000000"},
+		{value: "1e-18", ore: "1"},
+		{value: "0.0000000000000000010", ore: "1"},
+		{value: "0.0000000000000000001", errMessage: "more than 18 decimals"},
+		{value: "1e-19", errMessage: "more than 18 decimals"},
+		{value: "-1", errMessage: "negative"},
+		{value: "-0.5", errMessage: "negative"},
+		{value: "-1e3", errMessage: "negative"},
+		{value: "1/2", errMessage: "bad amount"},
+		{value: "1,5", errMessage: "bad amount"},
+		{value: "1 000", errMessage: "bad amount"},
+		{value: "abc", errMessage: "bad amount"},
+		{value: "010", ore: "10000000000000000000"},
+		{value: "0x10", errMessage: "bad amount"},
+		{value: "0b1", errMessage: "bad amount"},
+		{value: "1_000", errMessage: "bad amount"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			amount, err := ParseAmount(test.value)
+			if test.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errMessage) {
+					t.Fatalf("expected error with %q, got %v (amount %v)", test.errMessage, err, amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if amount.Ore().String() != test.ore {
+				t.Errorf("expected %v ore, got %v", test.ore, amount.Ore())
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "github.com/core-coin/go-core/v2/crypto"
+
+type Approval struct {
+	BatchHash string `json:"batch_hash"`
+	Approver  string `json:"approver"`
+	Signature string `json:"signature"`
+}
+
+type ApprovalUseCase interface {
+	//BatchHash is computing a canonical hash of a batch of signed transactions
+	BatchHash(signedTxs []string) (string, error)
+	//Approve signs the canonical hash of a batch with the approver's private key
+	Approve(signedTxs []string, key *crypto.PrivateKey) (*Approval, error)
+	//VerifyApprovals checks that a batch carries at least required valid approvals from the listed approvers
+	VerifyApprovals(signedTxs []string, approvals []*Approval, approvers []string, required int) error
+	//GetApprovalsFromFile is reading approvals from a file, missing file means no approvals
+	GetApprovalsFromFile(fileName string) ([]*Approval, error)
+	//WriteApprovalsToFile is writing approvals into a file in JSON format
+	WriteApprovalsToFile(approvals []*Approval, fileName string) error
+	//GetApproversFromFile is reading approver public keys (one per line) from a file
+	GetApproversFromFile(fileName string) ([]string, error)
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "fmt"
+
+// Config holds values of flags by flag name, written as they would be on command line
+type Config struct {
+	// Profile is used when no profile is selected by flag or environment
+	Profile string
+	// Settings apply to every profile
+	Settings map[string]string
+	// Profiles are named settings overriding Settings, e.g. mainnet and devin
+	Profiles map[string]map[string]string
+}
+
+// Resolve returns settings of profile on top of common settings, profile may be empty
+func (c *Config) Resolve(profile string) (map[string]string, error) {
+	settings := map[string]string{}
+	for name, value := range c.Settings {
+		settings[name] = value
+	}
+	if profile == "" {
+		return settings, nil
+	}
+	overrides, ok := c.Profiles[profile]
+	if !ok {
+		return nil, fmt.Errorf("profile %q is not found in config", profile)
+	}
+	for name, value := range overrides {
+		settings[name] = value
+	}
+	return settings, nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+// CSVFields are transaction fields which can be mapped to CSV columns, in the order of files without titles
+var CSVFields = []string{"from", "to", "amount", "energy_limit", "energy_price", "nonce"}
+
+// CSVFormat describes layout of CSV files and XLSX sheets with transactions
+type CSVFormat struct {
+	// Delimiter separates fields, comma if not set
+	Delimiter rune
+	// Comment starts lines which are skipped, no comments if not set
+	Comment rune
+	// Titles forces reading the first line as titles, otherwise it is read as titles when it names the to column
+	Titles bool
+	// ThousandsSeparator is removed from numbers, DecimalSeparator is read as decimal point
+	ThousandsSeparator string
+	DecimalSeparator   string
+	// Sheet is name or 1-based number of XLSX sheet, the first sheet if not set
+	Sheet string
+	// Columns maps fields from CSVFields to titles or 1-based numbers of source columns
+	Columns map[string]string
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+)
+
+// EnergyPriceStrategy describes how energy price of transactions without price is computed
+type EnergyPriceStrategy struct {
+	// Multiplier is applied to the network price before Premium (in ore) is added, it must be positive
+	Multiplier float64
+	Premium    *big.Int
+	// MaxPrice is a cap the price is clamped to, nil means no cap
+	MaxPrice *big.Int
+	// Percentile of energy prices of transactions in the last Blocks blocks is used as network price if it is above 0
+	Percentile int
+	Blocks     uint64
+	// Wait is how long to wait for the network price to drop below the cap, zero means to refuse at once
+	Wait         time.Duration
+	PollInterval time.Duration
+}
+
+type EnergyPriceUseCase interface {
+	//GetEnergyPrice is computing energy price by strategy once per run and returns the same price afterwards
+	GetEnergyPrice() (*big.Int, error)
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ReservedColumns cannot be extra columns, they name signed transaction and hash in output files
+var ReservedColumns = []string{"signed_tx", "hash"}
+
+// transactionFields is used to marshal known fields of Transaction without its methods
+type transactionFields Transaction
+
+// IsReservedColumn tells whether column name is taken by a field or output files
+func IsReservedColumn(name string) bool {
+	for _, field := range CSVFields {
+		if name == field {
+			return true
+		}
+	}
+	for _, column := range ReservedColumns {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalJSON writes extra columns next to transaction fields
+func (t Transaction) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(transactionFields(t))
+	if err != nil {
+		return nil, err
+	}
+	return AppendExtra(data, t.Extra)
+}
+
+// UnmarshalJSON reads all unknown keys as extra columns
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	fields := transactionFields{}
+	err := json.Unmarshal(data, &fields)
+	if err != nil {
+		return err
+	}
+	extra, err := ParseExtra(data, CSVFields...)
+	if err != nil {
+		return err
+	}
+	*t = Transaction(fields)
+	t.Extra = extra
+	return nil
+}
+
+// ParseExtra returns keys of JSON object other than known ones, values which are not strings are kept as JSON
+func ParseExtra(data []byte, known ...string) (map[string]string, error) {
+	object := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range known {
+		delete(object, key)
+	}
+	if len(object) == 0 {
+		return nil, nil
+	}
+	extra := map[string]string{}
+	for key, raw := range object {
+		if IsReservedColumn(key) {
+			return nil, fmt.Errorf("column %q is reserved", key)
+		}
+		var value string
+		if json.Unmarshal(raw, &value) != nil {
+			value = string(raw)
+		}
+		extra[key] = value
+	}
+	return extra, nil
+}
+
+// AppendExtra adds extra columns in sorted order to marshaled JSON object
+func AppendExtra(object []byte, extra map[string]string) ([]byte, error) {
+	if len(extra) == 0 {
+		return object, nil
+	}
+	keys := make([]string, 0, len(extra))
+	for key := range extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(bytes.TrimSuffix(bytes.TrimSpace(object), []byte("}")))
+	for i, key := range keys {
+		if i > 0 || buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(extra[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Extras returns extra columns of every transaction, nil if no transaction has them
+func (l TransactionList) Extras() []map[string]string {
+	for _, tx := range l {
+		if len(tx.Extra) > 0 {
+			extras := make([]map[string]string, len(l))
+			for i, tx := range l {
+				extras[i] = tx.Extra
+			}
+			return extras
+		}
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+package domain
+
+type FlowControlUseCase interface {
+	//WaitForSlot is waiting until the sender has less than the allowed number of transactions in flight before nonce
+	WaitForSlot(sender string, nonce uint64) error
+}
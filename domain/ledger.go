@@ -0,0 +1,25 @@
+package domain
+
+import "time"
+
+// DefaultIdempotencyKey are fields and extra columns identifying a payment in the ledger
+var DefaultIdempotencyKey = []string{"from", "to", "amount", "reference"}
+
+// LedgerEntry is a payment streamed by an earlier run
+type LedgerEntry struct {
+	Key      string    `json:"key"`
+	Hash     string    `json:"hash"`
+	File     string    `json:"file"`
+	Streamed time.Time `json:"streamed"`
+	// Row is the earlier 1-based row of the same batch with the key, for payments repeated within the batch
+	Row int `json:"-"`
+}
+
+type LedgerUseCase interface {
+	//FindDuplicates is looking up rows in the ledger of streamed payments
+	// Returns the earlier entry of every row, nil for rows which were not streamed before
+	// Rows repeating an earlier row of txs get an entry with Row set
+	FindDuplicates(txs TransactionList) ([]*LedgerEntry, error)
+	//Record is adding rows streamed with hashes to the ledger, rows with empty hash are skipped
+	Record(txs TransactionList, hashes []string, file string) error
+}
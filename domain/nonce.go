@@ -0,0 +1,39 @@
+package domain
+
+// NonceReport describes nonce state of a sender
+type NonceReport struct {
+	Address string   `json:"address"`
+	Latest  uint64   `json:"latest"`
+	Pending uint64   `json:"pending"`
+	Queued  []uint64 `json:"queued"`
+	Gaps    []uint64 `json:"gaps"`
+}
+
+type NonceUseCase interface {
+	//InspectNonces is comparing latest and pending transaction counts of senders with the node's transaction pool
+	// Returns a report for every sender with queued transactions and nonce gaps before them
+	InspectNonces(addresses []string) ([]*NonceReport, error)
+	//GetGapFillingTxs is building zero-value self-sends for nonce gaps of a sender
+	GetGapFillingTxs(report *NonceReport) (TransactionList, error)
+}
+
+// NonceRange is count consecutive nonces of a sender from Start
+type NonceRange struct {
+	Sender string
+	Start  uint64
+	Count  uint64
+}
+
+type NonceReservationUseCase interface {
+	//NextNonce returns the first nonce a reservation of the sender would get, nothing is reserved
+	NextNonce(sender string) (uint64, error)
+	//ReserveNonces is atomically reserving the range for this process
+	// Fails if the range is not free any more, e.g. another run reserved it meanwhile
+	ReserveNonces(r NonceRange) error
+	//ReleaseNonces is giving up the reserved range, unless a later reservation of the sender follows it
+	ReleaseNonces(r NonceRange) error
+	//KeepNonces is marking the reserved range as saved or streamed, it does not expire until the chain passes it
+	KeepNonces(r NonceRange) error
+	//RefreshNonces is renewing the reserved range of a run which waits to stream it, so it does not expire
+	RefreshNonces(r NonceRange) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+type Recipient struct {
+	Address string `json:"address" csv:"address"`
+	Weight  string `json:"weight" csv:"weight"`
+}
+
+// PlanOptions limits payouts of a distribution, zero limits are not applied
+type PlanOptions struct {
+	From        string
+	MinPayout   Amount
+	MaxPayout   Amount
+	RemainderTo string
+}
+
+type PlanUseCase interface {
+	//GetRecipientsFromFile is reading recipients with weights from a CSV or JSON file
+	GetRecipientsFromFile(fileName string) ([]*Recipient, error)
+	//Plan is splitting total across recipients in proportion to their weights
+	// Recipients below minimum payout get nothing, payouts above maximum are capped and the rest is redistributed,
+	// the rounding remainder goes to the RemainderTo recipient, or to another one if it would exceed maximum payout
+	Plan(total Amount, recipients []*Recipient, options *PlanOptions) (TransactionList, error)
+}
@@ -0,0 +1,21 @@
+package domain
+
+type Policy struct {
+	MaxAmount      Amount   `json:"max_amount"`
+	MaxBatchTotal  Amount   `json:"max_batch_total"`
+	MaxSenderTotal Amount   `json:"max_sender_total"`
+	MaxEnergyPrice string   `json:"max_energy_price"`
+	Allowlist      []string `json:"allowlist"`
+	Denylist       []string `json:"denylist"`
+	AllowZeroValue bool     `json:"allow_zero_value"`
+	AllowSelfSend  bool     `json:"allow_self_send"`
+	AllowContracts bool     `json:"allow_contracts"`
+}
+
+type PolicyUseCase interface {
+	//Check is validating transactions against the spending policy
+	// Returns an error listing every row and the rule it broke, totals add up over calls for batches checked in chunks
+	Check(txs TransactionList) error
+	//Reset is starting totals and row numbers of a new batch
+	Reset()
+}
@@ -0,0 +1,32 @@
+package domain
+
+// Kinds of discrepancies found by reconciliation
+const (
+	// DiscrepancyMissing is a payment without mined transaction, or a listed transaction the node does not know
+	DiscrepancyMissing = "missing"
+	// DiscrepancyPending is a listed transaction which is not mined yet
+	DiscrepancyPending = "pending"
+	// DiscrepancyFailed is a payment mined with failed status
+	DiscrepancyFailed = "failed"
+	// DiscrepancyMismatch is a payment whose transaction has other recipient, value or sender
+	DiscrepancyMismatch = "mismatch"
+	// DiscrepancyDuplicated is a payment mined more than once, or a transaction listed twice
+	DiscrepancyDuplicated = "duplicated"
+	// DiscrepancyUnexpected is a transaction of the sender which pays nothing of the file
+	DiscrepancyUnexpected = "unexpected"
+)
+
+// Discrepancy is a difference between intended payments and the chain, Row is 1-based row of transactions, 0 if none
+type Discrepancy struct {
+	Kind   string
+	Row    int
+	Hash   string
+	Detail string
+}
+
+type ReconcileUseCase interface {
+	//Reconcile is checking on chain that every intended payment was mined once with its recipient, value and sender
+	// Transactions of the senders in the nonce range of txIDs which are not listed are unexpected
+	// Returns discrepancies, none if everything matches
+	Reconcile(txs TransactionList, txIDs []string) ([]*Discrepancy, error)
+}
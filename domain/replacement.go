@@ -0,0 +1,20 @@
+package domain
+
+import "github.com/core-coin/go-core/v2/crypto"
+
+// Replacement links a pending transaction with the transaction which replaced it
+type Replacement struct {
+	OldHash string `json:"old_hash"`
+	NewHash string `json:"new_hash"`
+	From    string `json:"from"`
+	Nonce   uint64 `json:"nonce"`
+}
+
+type ReplacementUseCase interface {
+	//BumpTxs is re-signing still pending transactions with the same nonce and energy price raised by factor and streaming them
+	// Returns replacements of streamed transactions
+	BumpTxs(txIDs []string, key *crypto.PrivateKey, factor float64) ([]*Replacement, error)
+	//GetCancellationTxs is building zero-value self-sends for pending and queued nonces of the sender in range [fromNonce, toNonce]
+	// Energy price is the energy price of the replaced transaction raised by factor, at least the network energy price
+	GetCancellationTxs(from string, fromNonce, toNonce uint64, factor float64) (TransactionList, error)
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// Statuses of a row in results report
+const (
+	// ResultSigned is a row which was signed but not streamed, e.g. saved to output file or dry run
+	ResultSigned = "signed"
+	// ResultStreamed is a row accepted by the node
+	ResultStreamed = "streamed"
+	// ResultFailed is a row rejected by the node, its lane stopped there
+	ResultFailed = "failed"
+	// ResultNotStreamed is a row left behind a failed row of the same lane
+	ResultNotStreamed = "not_streamed"
+	// ResultMined is a row mined successfully
+	ResultMined = "mined"
+	// ResultReverted is a row mined with failed status
+	ResultReverted = "reverted"
+	// ResultPending is a row which was not mined before tracking timed out
+	ResultPending = "pending"
+)
+
+// ResultColumns follow transaction fields in results report
+var ResultColumns = []string{"hash", "status", "error", "block_number", "energy_used", "fee"}
+
+// Result is the outcome of an input row, values are in ore
+type Result struct {
+	Transaction *Transaction
+	Hash        string
+	Status      string
+	Error       string
+	BlockNumber string
+	EnergyUsed  string
+	Fee         string
+}
+
+type ReportUseCase interface {
+	//GetResults is joining transactions with their signed transactions, rows start as signed
+	GetResults(txs TransactionList, signedTxs []string) ([]*Result, error)
+	//SetStreamOutcome is setting status and error of rows from hashes and errors returned by streaming
+	SetStreamOutcome(results []*Result, hashes []string, errs []error)
+	//TrackResults is polling receipts of streamed rows until all of them are mined or timeout passes
+	// Rows which are not mined by then stay pending
+	TrackResults(results []*Result, timeout time.Duration) error
+	//WriteResultsToFile is writing results into a CSV, JSON or JSON lines file, chosen by file extension
+	WriteResultsToFile(results []*Result, fileName string) error
+	//GetResultsFromFile is reading results report written by WriteResultsToFile
+	GetResultsFromFile(fileName string) ([]*Result, error)
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+)
+
+type ScheduleUseCase interface {
+	//WaitForEnergyPrice is polling network energy price until it drops to maxPrice or deadline passes
+	// Zero deadline means to wait for the price without a deadline
+	WaitForEnergyPrice(maxPrice *big.Int, deadline time.Time) error
+	//WaitForTime is waiting until the time comes
+	WaitForTime(notBefore time.Time) error
+	//WaitForBlock is polling block number until the chain reaches the block
+	// Fails when deadline passes first, zero deadline waits without limit
+	WaitForBlock(notBefore uint64, deadline time.Time) error
+}
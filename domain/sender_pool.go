@@ -0,0 +1,7 @@
+package domain
+
+type SenderPoolUseCase interface {
+	//AssignSenders is filling sender of every transaction from the pool
+	// Senders get rows in proportion to their balances and every sender must cover its rows with fees
+	AssignSenders(txs TransactionList, senders []string) error
+}
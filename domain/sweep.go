@@ -0,0 +1,7 @@
+package domain
+
+type SweepUseCase interface {
+	//GetSweepTxs is building transfers of whole balances minus fees from senders to the destination
+	// Senders whose balance does not cover the fee are skipped
+	GetSweepTxs(senders []string, to string) (TransactionList, error)
+}
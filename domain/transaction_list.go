@@ -1,32 +1,117 @@
 package domain
 
-import "github.com/core-coin/go-core/v2/crypto"
+import (
+	"math/big"
+	"sort"
+
+	"github.com/core-coin/go-core/v2/crypto"
+)
 
 type TransactionList []*Transaction
 
 type Transaction struct {
-	From        string  `json:"from" csv:"from"`
-	To          string  `json:"to" csv:"to"`
-	Amount      float64 `json:"amount" csv:"amount"`
-	EnergyLimit string  `json:"energy_limit" csv:"energy_limit"`
-	EnergyPrice string  `json:"energy_price" csv:"energy_price"`
-	Nonce       string  `json:"nonce" csv:"nonce"`
+	From        string `json:"from" csv:"from"`
+	To          string `json:"to" csv:"to"`
+	Amount      Amount `json:"amount" csv:"amount"`
+	EnergyLimit string `json:"energy_limit" csv:"energy_limit"`
+	EnergyPrice string `json:"energy_price" csv:"energy_price"`
+	Nonce       string `json:"nonce" csv:"nonce"`
+	// Extra holds other columns of the row, e.g. reference or invoice_id, they are not put on chain
+	// but are carried to signed transactions and transaction hashes files
+	Extra map[string]string `json:"-" csv:"-"`
+}
+
+// BatchSummary describes a batch for operator review, values are in ore
+type BatchSummary struct {
+	Count        int
+	Total        *big.Int
+	MaxFees      *big.Int
+	SenderTotals map[string]*big.Int
+	Largest      TransactionList
+}
+
+// Merge is adding other summary of the same batch, keeping largest payments of both
+func (s *BatchSummary) Merge(other *BatchSummary, largest int) {
+	s.Count += other.Count
+	s.Total.Add(s.Total, other.Total)
+	s.MaxFees.Add(s.MaxFees, other.MaxFees)
+	for sender, total := range other.SenderTotals {
+		if _, ok := s.SenderTotals[sender]; !ok {
+			s.SenderTotals[sender] = new(big.Int)
+		}
+		s.SenderTotals[sender].Add(s.SenderTotals[sender], total)
+	}
+	merged := append(append(TransactionList{}, s.Largest...), other.Largest...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Amount.Cmp(merged[j].Amount) > 0
+	})
+	if len(merged) > largest {
+		merged = merged[:largest]
+	}
+	s.Largest = merged
+}
+
+type NonceChecker interface {
+	//Check is checking a chunk for nonces used twice, also in earlier chunks, or already confirmed
+	Check(txs TransactionList) error
+	//Finish is checking that nonces of every sender are contiguous over all chunks
+	Finish() error
 }
 
 type TransactionListUseCase interface {
 	//StreamSignedTxs is receiving a file with signed transactions and stream them into a blockchain
 	// Returns a slice of IDs of sent transactions
 	StreamSignedTxs(signedTxs []string) ([]string, error)
+	//StreamSignedTxsInLanes is streaming transactions of different senders in parallel, keeping order within a sender
+	// Returns IDs of sent transactions in the order of signedTxs
+	StreamSignedTxsInLanes(signedTxs []string) ([]string, error)
+	//StreamSignedTxsByRow is streaming transactions in one lane, or in a lane per sender if parallel is set
+	// Returns hash of every streamed row and error of the row which stopped its lane
+	StreamSignedTxsByRow(signedTxs []string, parallel bool) ([]string, []error)
 	//WriteTxIDsToFile is receiving a slice of transaction IDs and write them to a file
-	WriteTxIDsToFile(txIDs []string, fileName string) error
+	// Extras are extra columns of the rows, nil if the batch has none
+	WriteTxIDsToFile(txIDs []string, extras []map[string]string, fileName string) error
 	//WriteTxIDsToConsole is receiving a slice of transaction IDs and write them to a console
 	WriteTxIDsToConsole(txIDs []string) error
-	//GetSignedTxsFromFile is reading signed transactions from a file
-	GetSignedTxsFromFile(fileName string) ([]string, error)
-	//GetTxsFromFile is reading transaction from a file and skip first row in CSV if missTitles is true
-	GetTxsFromFile(fileName string, missTitles bool) (TransactionList, error)
-	//SignTxs signs transactions with provided private key
+	//GetTxIDsFromFile is reading transaction IDs and extra columns of their rows from a file
+	GetTxIDsFromFile(fileName string) ([]string, []map[string]string, error)
+	//GetSignedTxsFromFile is reading signed transactions and extra columns of their rows from a file
+	GetSignedTxsFromFile(fileName string) ([]string, []map[string]string, error)
+	//GetTxsFromFile is reading transaction from a file, CSV files are read according to csvFormat
+	// Missing nonces are assigned from startNonces of the sender or from its pending nonce
+	GetTxsFromFile(fileName string, csvFormat *CSVFormat, startNonces map[string]uint64) (TransactionList, error)
+	//ReadTxsFromFile is reading transaction from a file without filling missing fields
+	ReadTxsFromFile(fileName string, csvFormat *CSVFormat) (TransactionList, error)
+	//ReadTxsInChunks is calling handle with consecutive chunks of at most size transactions read from a file
+	ReadTxsInChunks(fileName string, csvFormat *CSVFormat, size int, handle func(TransactionList) error) error
+	//FillTxs is filling missing nonces, energy prices and energy limits like GetTxsFromFile does
+	// Nonces taken from nonce reservation are only planned until ReserveNonces is called
+	FillTxs(txs TransactionList, startNonces map[string]uint64) (TransactionList, error)
+	//ReserveNonces is reserving nonces planned by FillTxs, it is called once the batch is confirmed and signed
+	ReserveNonces() error
+	//ReleaseNonces is giving up nonces reserved by ReserveNonces when the batch is not saved or streamed
+	ReleaseNonces()
+	//KeepNonces is keeping reserved nonces once the batch is saved or a transaction of it is streamed
+	// Kept nonces are not released any more
+	KeepNonces() error
+	//RefreshNonces is renewing reserved nonces while the run waits to stream them
+	RefreshNonces()
+	//CheckNonces is checking transactions for duplicate, non-contiguous or already confirmed nonces per sender
+	CheckNonces(txs TransactionList) error
+	//NewNonceChecker is checking nonces of a batch read in chunks, like CheckNonces does for a whole batch
+	NewNonceChecker() NonceChecker
+	//SignTxs signs transactions with provided private key, spending policy violations block signing
 	SignTxs(txs TransactionList, key *crypto.PrivateKey) ([]string, error)
-	//WriteSignedTxsToFile is writing signed transactions into a file in JSON format
-	WriteSignedTxsToFile(signedTxs []string, fileName string) error
+	//SignTxsWithKeys signs every transaction with the key of its sender
+	SignTxsWithKeys(txs TransactionList, keys map[string]*crypto.PrivateKey) ([]string, error)
+	//WriteTxsToFile is writing unsigned transactions into a JSON, JSON lines or CSV file with titles, chosen by file extension
+	WriteTxsToFile(txs TransactionList, fileName string) error
+	//AppendSignedTxsToFile is adding signed transactions to the end of a JSON lines file
+	AppendSignedTxsToFile(signedTxs []string, extras []map[string]string, fileName string) error
+	//WriteSignedTxsToFile is writing signed transactions into a file in JSON or JSON lines format, chosen by file extension
+	WriteSignedTxsToFile(signedTxs []string, extras []map[string]string, fileName string) error
+	//DecodeSignedTxs is decoding signed transactions and recovering their senders
+	DecodeSignedTxs(signedTxs []string) (TransactionList, error)
+	//Summarize is computing totals, maximum fees and the largest payments of a batch
+	Summarize(txs TransactionList, largest int) (*BatchSummary, error)
 }
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+)
+
+type energyPriceUsecase struct {
+	strategy *domain.EnergyPriceStrategy
+	price    *big.Int
+	logger   logger.Logger
+	rpc      rpcClient.Client
+}
+
+// NewEnergyPriceUsecase create new energy price usecase
+func NewEnergyPriceUsecase(strategy *domain.EnergyPriceStrategy, rpc rpcClient.Client, log logger.Logger) domain.EnergyPriceUseCase {
+	return &energyPriceUsecase{
+		strategy: strategy,
+		rpc:      rpc,
+		logger:   log,
+	}
+}
+
+// GetEnergyPrice is clamping the strategy price to the cap, it waits only while the network price itself is above the
+// cap if strategy allows it
+func (e *energyPriceUsecase) GetEnergyPrice() (*big.Int, error) {
+	if e.price != nil {
+		return new(big.Int).Set(e.price), nil
+	}
+
+	deadline := time.Now().Add(e.strategy.Wait)
+	for {
+		base, price, err := e.computePrice()
+		if err != nil {
+			return nil, err
+		}
+		if e.strategy.MaxPrice == nil || base.Cmp(e.strategy.MaxPrice) <= 0 {
+			if e.strategy.MaxPrice != nil && price.Cmp(e.strategy.MaxPrice) > 0 {
+				e.logger.Debugf("Energy price %v is clamped to the cap %v", price, e.strategy.MaxPrice)
+				price = new(big.Int).Set(e.strategy.MaxPrice)
+			}
+			e.logger.Infof("Using energy price %v", price)
+			e.price = price
+			return new(big.Int).Set(price), nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("network energy price %v is above the cap %v", base, e.strategy.MaxPrice)
+		}
+		e.logger.Infof("Network energy price %v is above the cap %v, waiting", base, e.strategy.MaxPrice)
+		if remaining > e.strategy.PollInterval {
+			remaining = e.strategy.PollInterval
+		}
+		time.Sleep(remaining)
+	}
+}
+
+// computePrice is applying multiplier and premium to the network price, returns both prices
+func (e *energyPriceUsecase) computePrice() (*big.Int, *big.Int, error) {
+	base, err := e.networkPrice()
+	if err != nil {
+		return nil, nil, err
+	}
+	price := new(big.Int).Set(base)
+	if e.strategy.Multiplier != 1 {
+		price, _ = new(big.Float).Mul(new(big.Float).SetInt(base), big.NewFloat(e.strategy.Multiplier)).Int(nil)
+	}
+	if e.strategy.Premium != nil {
+		price.Add(price, e.strategy.Premium)
+	}
+	e.logger.Debugf("Network energy price %v, strategy price %v", base, price)
+	return base, price, nil
+}
+
+// networkPrice is getting node's estimation or percentile of prices paid in recent blocks
+func (e *energyPriceUsecase) networkPrice() (*big.Int, error) {
+	if e.strategy.Percentile <= 0 {
+		price, err := e.rpc.EstimateEnergyPrice()
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(price), nil
+	}
+	head, err := e.rpc.GetBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	var prices []*big.Int
+	for number := head; number+e.strategy.Blocks > head && number <= head; number-- {
+		block, err := e.rpc.GetBlockByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			continue
+		}
+		for _, tx := range block.Transactions {
+			if tx.EnergyPrice == nil {
+				continue
+			}
+			prices = append(prices, tx.EnergyPrice.ToInt())
+		}
+		if number == 0 {
+			break
+		}
+	}
+	if len(prices) == 0 {
+		e.logger.Debugf("No transactions in the last %v blocks, using node's energy price", e.strategy.Blocks)
+		price, err := e.rpc.EstimateEnergyPrice()
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(price), nil
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].Cmp(prices[j]) < 0 })
+	index := (len(prices)*e.strategy.Percentile+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return prices[index], nil
+}
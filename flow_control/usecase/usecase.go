@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+)
+
+const (
+	// maxNonceRetries is the number of failed nonce polls in a row which stop the lane
+	maxNonceRetries = 5
+	// firstRetryDelay is doubled after every failed nonce poll
+	firstRetryDelay = time.Second
+)
+
+type flowControlUsecase struct {
+	maxInFlight  uint64
+	pollInterval time.Duration
+	logger       logger.Logger
+	rpc          rpcClient.Client
+}
+
+// NewFlowControlUsecase create new flow control usecase allowing maxInFlight pending transactions per sender
+func NewFlowControlUsecase(maxInFlight uint64, pollInterval time.Duration, rpc rpcClient.Client, log logger.Logger) domain.FlowControlUseCase {
+	return &flowControlUsecase{
+		maxInFlight:  maxInFlight,
+		pollInterval: pollInterval,
+		rpc:          rpc,
+		logger:       log,
+	}
+}
+
+// WaitForSlot is counting transactions in flight as the ones between the latest nonce and nonce,
+// so it works for transactions sent by other processes too. Failed nonce polls are retried with growing delay.
+func (f *flowControlUsecase) WaitForSlot(sender string, nonce uint64) error {
+	logged := false
+	failures := 0
+	delay := firstRetryDelay
+	for {
+		latest, err := f.rpc.GetAccountNonce(sender, "latest")
+		if err != nil {
+			failures++
+			if failures > maxNonceRetries {
+				return fmt.Errorf("cannot get nonce of sender %v after %v retries: %v", sender, maxNonceRetries, err)
+			}
+			f.logger.Warnf("Cannot get nonce of sender %v: %v, retrying in %v", sender, err, delay)
+			time.Sleep(delay)
+			delay *= 2
+			continue
+		}
+		failures = 0
+		delay = firstRetryDelay
+		if nonce < latest || nonce-latest < f.maxInFlight {
+			return nil
+		}
+		if !logged {
+			f.logger.Infof("Sender %v has %v transactions in flight, waiting for a free slot", sender, nonce-latest)
+			logged = true
+		}
+		time.Sleep(f.pollInterval)
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"math/big"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/core-coin/go-core/v2/common/hexutil"
+
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
 )
 
 type RPCClient struct {
@@ -115,3 +118,111 @@ func (r *RPCClient) EstimateEnergyPrice() (int64, error) {
 	}
 	return int64(energy), nil
 }
+
+func (r *RPCClient) GetCode(account, status string) ([]byte, error) {
+	params := []string{account, status}
+	rpcResp, err := r.doPost(r.Url, "xcb_getCode", params)
+	if err != nil {
+		return nil, err
+	}
+	var reply string
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.Decode(reply)
+}
+
+func (r *RPCClient) GetTransactionByHash(hash string) (*rpcClient.Transaction, error) {
+	params := []string{hash}
+	rpcResp, err := r.doPost(r.Url, "xcb_getTransactionByHash", params)
+	if err != nil {
+		return nil, err
+	}
+	var reply *rpcClient.Transaction
+	if rpcResp.Result == nil {
+		return reply, nil
+	}
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (r *RPCClient) GetTransactionReceipt(hash string) (*rpcClient.Receipt, error) {
+	params := []string{hash}
+	rpcResp, err := r.doPost(r.Url, "xcb_getTransactionReceipt", params)
+	if err != nil {
+		return nil, err
+	}
+	var reply *rpcClient.Receipt
+	if rpcResp.Result == nil {
+		return reply, nil
+	}
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (r *RPCClient) GetTxPoolContent() (*rpcClient.TxPoolContent, error) {
+	rpcResp, err := r.doPost(r.Url, "txpool_content", nil)
+	if err != nil {
+		return nil, err
+	}
+	if rpcResp.Result == nil {
+		return nil, errors.New("node returned no transaction pool content")
+	}
+	var reply *rpcClient.TxPoolContent
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (r *RPCClient) GetBlockNumber() (uint64, error) {
+	rpcResp, err := r.doPost(r.Url, "xcb_blockNumber", nil)
+	if err != nil {
+		return 0, err
+	}
+	var reply string
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.DecodeUint64(reply)
+}
+
+func (r *RPCClient) GetBlockByNumber(number uint64) (*rpcClient.Block, error) {
+	params := []interface{}{hexutil.EncodeUint64(number), true}
+	rpcResp, err := r.doPost(r.Url, "xcb_getBlockByNumber", params)
+	if err != nil {
+		return nil, err
+	}
+	var reply *rpcClient.Block
+	if rpcResp.Result == nil {
+		return reply, nil
+	}
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (r *RPCClient) GetBalance(account, status string) (*big.Int, error) {
+	params := []string{account, status}
+	rpcResp, err := r.doPost(r.Url, "xcb_getBalance", params)
+	if err != nil {
+		return nil, err
+	}
+	var reply string
+	err = json.Unmarshal(*rpcResp.Result, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return hexutil.DecodeBig(reply)
+}
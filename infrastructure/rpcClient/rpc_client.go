@@ -1,7 +1,54 @@
 package rpcClient
 
+import (
+	"math/big"
+
+	"github.com/core-coin/go-core/v2/common/hexutil"
+)
+
 type Client interface {
 	SendRawTransaction(data string) (string, error)
 	GetAccountNonce(account, status string) (uint64, error)
 	EstimateEnergyPrice() (int64, error)
+	GetCode(account, status string) ([]byte, error)
+	GetTransactionByHash(hash string) (*Transaction, error)
+	GetTransactionReceipt(hash string) (*Receipt, error)
+	GetTxPoolContent() (*TxPoolContent, error)
+	GetBlockNumber() (uint64, error)
+	GetBalance(account, status string) (*big.Int, error)
+	GetBlockByNumber(number uint64) (*Block, error)
+}
+
+// Transaction is a transaction as returned by gocore RPC API, BlockNumber is nil for pending transactions
+type Transaction struct {
+	BlockNumber *hexutil.Big   `json:"blockNumber"`
+	From        string         `json:"from"`
+	Energy      hexutil.Uint64 `json:"energy"`
+	EnergyPrice *hexutil.Big   `json:"energyPrice"`
+	Hash        string         `json:"hash"`
+	Input       hexutil.Bytes  `json:"input"`
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	To          *string        `json:"to"`
+	Value       *hexutil.Big   `json:"value"`
+}
+
+// Receipt is a receipt of mined transaction as returned by gocore RPC API, Status is 1 for success
+type Receipt struct {
+	BlockNumber *hexutil.Big   `json:"blockNumber"`
+	EnergyUsed  hexutil.Uint64 `json:"energyUsed"`
+	Status      hexutil.Uint64 `json:"status"`
+	TxHash      string         `json:"transactionHash"`
+}
+
+// TxPoolContent is a content of node's transaction pool grouped by sender address and nonce
+type TxPoolContent struct {
+	Pending map[string]map[string]*Transaction `json:"pending"`
+	Queued  map[string]map[string]*Transaction `json:"queued"`
+}
+
+// Block is a block with full transactions as returned by gocore RPC API
+type Block struct {
+	Number       *hexutil.Big   `json:"number"`
+	Timestamp    hexutil.Uint64 `json:"timestamp"`
+	Transactions []*Transaction `json:"transactions"`
 }
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// maxLedgerLine is the longest line of the ledger
+const maxLedgerLine = 1024 * 1024
+
+type ledgerUsecase struct {
+	fileName string
+	key      []string
+	logger   logger.Logger
+
+	// entries are read once per run
+	entries map[string]*domain.LedgerEntry
+}
+
+// NewLedgerUsecase create new ledger usecase keeping entries in JSON lines file, payments are identified by key fields
+func NewLedgerUsecase(fileName string, key []string, log logger.Logger) domain.LedgerUseCase {
+	return &ledgerUsecase{
+		fileName: fileName,
+		key:      key,
+		logger:   log,
+	}
+}
+
+// DefaultLedgerFile returns ledger.jsonl in pigeon's user config directory
+func DefaultLedgerFile() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pigeon", "ledger.jsonl"), nil
+}
+
+// FindDuplicates is reading the whole ledger on the first call, a missing ledger has no entries.
+// Rows of txs are compared with each other by the same key, the ledger entry is preferred.
+func (l *ledgerUsecase) FindDuplicates(txs domain.TransactionList) ([]*domain.LedgerEntry, error) {
+	if l.entries == nil {
+		entries, err := l.readEntries()
+		if err != nil {
+			return nil, err
+		}
+		l.entries = entries
+	}
+	duplicates := make([]*domain.LedgerEntry, len(txs))
+	rows := map[string]int{}
+	for i, tx := range txs {
+		key := l.keyOf(tx)
+		if entry, ok := l.entries[key]; ok {
+			duplicates[i] = entry
+		} else if row, ok := rows[key]; ok {
+			duplicates[i] = &domain.LedgerEntry{Key: key, Row: row}
+		}
+		if _, ok := rows[key]; !ok {
+			rows[key] = i + 1
+		}
+	}
+	return duplicates, nil
+}
+
+// Record is appending an entry per line, so the ledger is not rewritten by every run, entries read already are updated
+func (l *ledgerUsecase) Record(txs domain.TransactionList, hashes []string, file string) error {
+	err := os.MkdirAll(filepath.Dir(l.fileName), 0700)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(l.fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	now := time.Now().UTC()
+	count := 0
+	for i, tx := range txs {
+		if i >= len(hashes) || hashes[i] == "" {
+			continue
+		}
+		entry := &domain.LedgerEntry{Key: l.keyOf(tx), Hash: hashes[i], File: file, Streamed: now}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		if _, ok := l.entries[entry.Key]; !ok && l.entries != nil {
+			l.entries[entry.Key] = entry
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+		count++
+	}
+	err = w.Flush()
+	if err != nil {
+		out.Close()
+		return err
+	}
+	l.logger.Debugf("Recorded %v payments in ledger %v", count, l.fileName)
+	return out.Close()
+}
+
+// readEntries is mapping keys to their first entry
+func (l *ledgerUsecase) readEntries() (map[string]*domain.LedgerEntry, error) {
+	entries := map[string]*domain.LedgerEntry{}
+	in, err := os.Open(l.fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxLedgerLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+		entry := &domain.LedgerEntry{}
+		err = json.Unmarshal(data, entry)
+		if err != nil {
+			return nil, fmt.Errorf("ledger %v line %v: %v", l.fileName, line, err)
+		}
+		if _, ok := entries[entry.Key]; !ok {
+			entries[entry.Key] = entry
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// keyOf is joining name=value of key fields, addresses are normalized and amounts are in ore,
+// extra columns are looked up by name regardless of case
+func (l *ledgerUsecase) keyOf(tx *domain.Transaction) string {
+	parts := make([]string, len(l.key))
+	for i, name := range l.key {
+		var value string
+		switch name {
+		case "from":
+			value = pkg.NormalizeAddress(tx.From)
+		case "to":
+			value = pkg.NormalizeAddress(tx.To)
+		case "amount":
+			value = tx.Amount.Ore().String()
+		case "energy_limit":
+			value = tx.EnergyLimit
+		case "energy_price":
+			value = tx.EnergyPrice
+		case "nonce":
+			value = tx.Nonce
+		default:
+			value = extraValue(tx.Extra, name)
+		}
+		parts[i] = name + "=" + value
+	}
+	return strings.Join(parts, "|")
+}
+
+// extraValue returns extra column by name, or by name in other case
+func extraValue(extra map[string]string, name string) string {
+	if value, ok := extra[name]; ok {
+		return value
+	}
+	for column, value := range extra {
+		if strings.EqualFold(column, name) {
+			return value
+		}
+	}
+	return ""
+}
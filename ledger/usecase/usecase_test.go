@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/logger/zap"
+)
+
+const (
+	alice = "cb000000000000000000000000000000000000000001"
+	bob   = "cb000000000000000000000000000000000000000002"
+)
+
+func payment(from, to string, ore int64, extra map[string]string) *domain.Transaction {
+	return &domain.Transaction{From: from, To: to, Amount: domain.NewAmount(big.NewInt(ore)), Extra: extra}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	streamed := payment(alice, bob, 100, map[string]string{"reference": "PAY-1"})
+	tests := []struct {
+		name string
+		key  []string
+		txs  domain.TransactionList
+		// duplicates are 1-based rows of the batch, -1 for the ledger entry, 0 for no duplicate
+		duplicates []int
+	}{
+		{
+			name:       "same payment",
+			txs:        domain.TransactionList{payment(alice, bob, 100, map[string]string{"reference": "PAY-1"})},
+			duplicates: []int{-1},
+		},
+		{
+			name:       "addresses in other case and column name in other case",
+			txs:        domain.TransactionList{payment("CB000000000000000000000000000000000000000001", "CB000000000000000000000000000000000000000002", 100, map[string]string{"Reference": "PAY-1"})},
+			duplicates: []int{-1},
+		},
+		{
+			name: "other amount, reference or sender",
+			txs: domain.TransactionList{
+				payment(alice, bob, 101, map[string]string{"reference": "PAY-1"}),
+				payment(alice, bob, 100, map[string]string{"reference": "PAY-2"}),
+				payment(bob, bob, 100, map[string]string{"reference": "PAY-1"}),
+				payment(alice, bob, 100, nil),
+			},
+			duplicates: []int{0, 0, 0, 0},
+		},
+		{
+			name: "repeated rows of the batch",
+			txs: domain.TransactionList{
+				payment(alice, bob, 5, map[string]string{"reference": "PAY-3"}),
+				payment(alice, bob, 6, map[string]string{"reference": "PAY-3"}),
+				payment(alice, bob, 5, map[string]string{"reference": "PAY-3"}),
+				payment(alice, bob, 5, map[string]string{"reference": "PAY-3"}),
+			},
+			duplicates: []int{0, 0, 1, 1},
+		},
+		{
+			name: "ledger entry is preferred to the earlier row",
+			txs: domain.TransactionList{
+				payment(alice, bob, 100, map[string]string{"reference": "PAY-1"}),
+				payment(alice, bob, 100, map[string]string{"reference": "PAY-1"}),
+			},
+			duplicates: []int{-1, -1},
+		},
+		{
+			name: "custom key ignores other fields",
+			key:  []string{"to", "invoice_id"},
+			txs: domain.TransactionList{
+				payment(bob, bob, 1, map[string]string{"invoice_id": "7"}),
+				payment(alice, bob, 2, map[string]string{"INVOICE_ID": "7"}),
+			},
+			duplicates: []int{0, 1},
+		},
+	}
+
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key := test.key
+			if key == nil {
+				key = domain.DefaultIdempotencyKey
+			}
+			fileName := filepath.Join(t.TempDir(), "ledger.jsonl")
+			err := NewLedgerUsecase(fileName, key, log).Record(domain.TransactionList{streamed}, []string{"0x1"}, "payouts.csv")
+			if err != nil {
+				t.Fatal(err)
+			}
+			duplicates, err := NewLedgerUsecase(fileName, key, log).FindDuplicates(test.txs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, entry := range duplicates {
+				expected := test.duplicates[i]
+				switch {
+				case expected == 0 && entry != nil:
+					t.Errorf("row %v: unexpected duplicate %+v", i+1, entry)
+				case expected == -1 && (entry == nil || entry.Hash != "0x1" || entry.File != "payouts.csv"):
+					t.Errorf("row %v: expected ledger entry, got %+v", i+1, entry)
+				case expected > 0 && (entry == nil || entry.Row != expected):
+					t.Errorf("row %v: expected repeat of row %v, got %+v", i+1, expected, entry)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordUpdatesEntriesReadAlready(t *testing.T) {
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	uc := NewLedgerUsecase(filepath.Join(t.TempDir(), "ledger.jsonl"), domain.DefaultIdempotencyKey, log)
+	txs := domain.TransactionList{payment(alice, bob, 1, nil), payment(alice, bob, 2, nil)}
+	duplicates, err := uc.FindDuplicates(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplicates[0] != nil || duplicates[1] != nil {
+		t.Fatal("expected no duplicates in empty ledger")
+	}
+	// the second row failed to stream and is not recorded
+	err = uc.Record(txs, []string{"0x1", ""}, "a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicates, err = uc.FindDuplicates(txs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if duplicates[0] == nil || duplicates[0].Hash != "0x1" || duplicates[1] != nil {
+		t.Errorf("expected only the first row in ledger, got %+v and %+v", duplicates[0], duplicates[1])
+	}
+}
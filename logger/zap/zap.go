@@ -45,7 +45,7 @@ func (l *apiLogger) InitLogger() {
 	config.EncodeTime = zapcore.ISO8601TimeEncoder
 	consoleEncoder := zapcore.NewConsoleEncoder(config)
 	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), logLevel),
+		zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stderr), logLevel),
 	)
 	logger := zap.New(core)
 	l.sugarLogger = logger.Sugar()
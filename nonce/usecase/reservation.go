@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const (
+	lockFileName  = "nonces.lock"
+	stateFileName = "nonces.json"
+
+	lockRetryInterval = 100 * time.Millisecond
+	lockTimeout       = 30 * time.Second
+	staleLockAge      = 5 * time.Minute
+)
+
+// reservedNonce is a persisted state of a sender. Nonces below Kept belong to saved or streamed batches and are kept
+// until the chain passes them, nonces from Kept to Next expire ttl after Updated.
+type reservedNonce struct {
+	Next    uint64    `json:"next"`
+	Kept    uint64    `json:"kept"`
+	Updated time.Time `json:"updated"`
+}
+
+type nonceReservationUsecase struct {
+	dir    string
+	ttl    time.Duration
+	logger logger.Logger
+	rpc    rpcClient.Client
+}
+
+// NewNonceReservationUsecase create new nonce reservation usecase keeping its state in dir.
+// Reservations of batches which were neither saved nor streamed are given up when they are older than ttl.
+func NewNonceReservationUsecase(dir string, ttl time.Duration, rpc rpcClient.Client, log logger.Logger) domain.NonceReservationUseCase {
+	return &nonceReservationUsecase{
+		dir:    dir,
+		ttl:    ttl,
+		rpc:    rpc,
+		logger: log,
+	}
+}
+
+// NextNonce is the highest of the reserved and the pending nonce
+func (n *nonceReservationUsecase) NextNonce(sender string) (uint64, error) {
+	state, err := n.readState()
+	if err != nil {
+		return 0, err
+	}
+	return n.nextNonce(state, sender)
+}
+
+// ReserveNonces is reserving nonces under lock file if the range starts at or above the next free nonce
+func (n *nonceReservationUsecase) ReserveNonces(r domain.NonceRange) error {
+	return n.update(func(state map[string]*reservedNonce) error {
+		next, err := n.nextNonce(state, r.Sender)
+		if err != nil {
+			return err
+		}
+		if r.Start < next {
+			return fmt.Errorf("nonces %v-%v of sender %v were taken by another run meanwhile, run again", r.Start, next-1, r.Sender)
+		}
+		key := pkg.NormalizeAddress(r.Sender)
+		if state[key] == nil {
+			state[key] = &reservedNonce{}
+		}
+		state[key].Next = r.Start + r.Count
+		state[key].Updated = time.Now()
+		n.logger.Debugf("Reserved nonces %v-%v of sender %v", r.Start, r.Start+r.Count-1, r.Sender)
+		return nil
+	})
+}
+
+// ReleaseNonces is moving the reservation back to the start of the range if it still ends with the range
+func (n *nonceReservationUsecase) ReleaseNonces(r domain.NonceRange) error {
+	return n.update(func(state map[string]*reservedNonce) error {
+		key := pkg.NormalizeAddress(r.Sender)
+		reserved, ok := state[key]
+		if !ok || reserved.Next != r.Start+r.Count {
+			n.logger.Debugf("Nonces %v-%v of sender %v are followed by another reservation, keeping them", r.Start, r.Start+r.Count-1, r.Sender)
+			return nil
+		}
+		reserved.Next = r.Start
+		n.logger.Debugf("Released nonces %v-%v of sender %v", r.Start, r.Start+r.Count-1, r.Sender)
+		return nil
+	})
+}
+
+// KeepNonces is moving Kept to the end of the range, so the range does not expire
+func (n *nonceReservationUsecase) KeepNonces(r domain.NonceRange) error {
+	return n.update(func(state map[string]*reservedNonce) error {
+		reserved, ok := state[pkg.NormalizeAddress(r.Sender)]
+		if !ok {
+			return fmt.Errorf("nonces %v-%v of sender %v are not reserved", r.Start, r.Start+r.Count-1, r.Sender)
+		}
+		if end := r.Start + r.Count; end > reserved.Kept {
+			reserved.Kept = end
+		}
+		if reserved.Kept > reserved.Next {
+			reserved.Next = reserved.Kept
+		}
+		n.logger.Debugf("Kept nonces %v-%v of sender %v", r.Start, r.Start+r.Count-1, r.Sender)
+		return nil
+	})
+}
+
+// RefreshNonces is renewing the time of the reservation if it still covers the range
+func (n *nonceReservationUsecase) RefreshNonces(r domain.NonceRange) error {
+	return n.update(func(state map[string]*reservedNonce) error {
+		reserved, ok := state[pkg.NormalizeAddress(r.Sender)]
+		if ok && reserved.Next >= r.Start+r.Count {
+			reserved.Updated = time.Now()
+		}
+		return nil
+	})
+}
+
+// update is changing state under lock file
+func (n *nonceReservationUsecase) update(change func(state map[string]*reservedNonce) error) error {
+	err := os.MkdirAll(n.dir, 0700)
+	if err != nil {
+		return err
+	}
+	unlock, err := n.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := n.readState()
+	if err != nil {
+		return err
+	}
+	err = change(state)
+	if err != nil {
+		return err
+	}
+	return n.writeState(state)
+}
+
+// nextNonce is taking reserved nonce of the sender unless the chain is past it. Kept nonces never expire,
+// the rest of the reservation expires after ttl.
+func (n *nonceReservationUsecase) nextNonce(state map[string]*reservedNonce, sender string) (uint64, error) {
+	pending, err := n.rpc.GetAccountNonce(sender, "pending")
+	if err != nil {
+		return 0, err
+	}
+	reserved, ok := state[pkg.NormalizeAddress(sender)]
+	if !ok {
+		return pending, nil
+	}
+	next := pending
+	if reserved.Kept > next {
+		next = reserved.Kept
+	}
+	if reserved.Next <= next {
+		return next, nil
+	}
+	if time.Since(reserved.Updated) >= n.ttl {
+		n.logger.Warnf("Reservation of nonces %v-%v of sender %v expired, continuing from nonce %v", next, reserved.Next-1, sender, next)
+		return next, nil
+	}
+	return reserved.Next, nil
+}
+
+// lock is creating lock file exclusively, lock files of crashed processes are removed after staleLockAge
+func (n *nonceReservationUsecase) lock() (func(), error) {
+	lockFile := filepath.Join(n.dir, lockFileName)
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_, err = file.WriteString(strconv.Itoa(os.Getpid()))
+			file.Close()
+			if err != nil {
+				os.Remove(lockFile)
+				return nil, err
+			}
+			return func() { os.Remove(lockFile) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(lockFile); err == nil && time.Since(info.ModTime()) > staleLockAge {
+			n.logger.Warnf("Removing stale nonce lock file %v", lockFile)
+			os.Remove(lockFile)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("cannot acquire nonce lock file %v, another pigeon is running", lockFile)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+func (n *nonceReservationUsecase) readState() (map[string]*reservedNonce, error) {
+	state := map[string]*reservedNonce{}
+	data, err := os.ReadFile(filepath.Join(n.dir, stateFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &state)
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// writeState is replacing state file at once so a crash never leaves it half written
+func (n *nonceReservationUsecase) writeState(state map[string]*reservedNonce) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile := filepath.Join(n.dir, stateFileName+".tmp")
+	err = os.WriteFile(tmpFile, data, 0600)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpFile, filepath.Join(n.dir, stateFileName))
+}
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger/zap"
+)
+
+const sender = "cb94495583d6a5be74918e6324a0a08170c60a72c01e"
+
+// fakeRPC is answering the same pending nonce for every sender
+type fakeRPC struct {
+	rpcClient.Client
+	pending uint64
+}
+
+func (f *fakeRPC) GetAccountNonce(account, status string) (uint64, error) {
+	return f.pending, nil
+}
+
+// step is an operation on the reservation of sender, next is the next nonce expected after it
+type step struct {
+	action     string
+	start      uint64
+	count      uint64
+	pending    uint64
+	age        time.Duration
+	next       uint64
+	errMessage string
+}
+
+func TestReservation(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "reservations follow each other",
+			steps: []step{
+				{action: "next", next: 5, pending: 5},
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "reserve", start: 8, count: 2, pending: 5, next: 10},
+			},
+		},
+		{
+			name: "taken range is refused",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "reserve", start: 5, count: 1, pending: 5, errMessage: "nonces 5-7 of sender"},
+				{action: "reserve", start: 3, count: 1, pending: 5, errMessage: "were taken by another run"},
+			},
+		},
+		{
+			name: "released range is free again",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "release", start: 5, count: 3, pending: 5, next: 5},
+			},
+		},
+		{
+			name: "release keeps range followed by another reservation",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "reserve", start: 8, count: 2, pending: 5, next: 10},
+				{action: "release", start: 5, count: 3, pending: 5, next: 10},
+				{action: "release", start: 8, count: 2, pending: 5, next: 8},
+			},
+		},
+		{
+			name: "chain passes reservation",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "next", pending: 9, next: 9},
+			},
+		},
+		{
+			name: "unsaved reservation expires",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "next", pending: 5, age: 59 * time.Minute, next: 8},
+				{action: "next", pending: 6, age: 61 * time.Minute, next: 6},
+			},
+		},
+		{
+			name: "kept reservation does not expire until the chain passes it",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "keep", start: 5, count: 3, pending: 5, next: 8},
+				{action: "next", pending: 5, age: 48 * time.Hour, next: 8},
+				{action: "release", start: 5, count: 3, pending: 5, age: 48 * time.Hour, next: 8},
+				{action: "next", pending: 8, next: 8},
+				{action: "next", pending: 10, next: 10},
+			},
+		},
+		{
+			name: "unsaved reservation after kept one expires to the kept end",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "keep", start: 5, count: 3, pending: 5, next: 8},
+				{action: "reserve", start: 8, count: 2, pending: 5, next: 10},
+				{action: "next", pending: 5, age: 2 * time.Hour, next: 8},
+			},
+		},
+		{
+			name: "refresh renews waiting reservation",
+			steps: []step{
+				{action: "reserve", start: 5, count: 3, pending: 5, next: 8},
+				{action: "refresh", start: 5, count: 3, pending: 5, age: 50 * time.Minute, next: 8},
+				{action: "next", pending: 5, age: 50 * time.Minute, next: 8},
+			},
+		},
+		{
+			name: "keep of unknown sender fails",
+			steps: []step{
+				{action: "keep", start: 5, count: 3, pending: 5, errMessage: "are not reserved"},
+			},
+		},
+	}
+
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rpc := &fakeRPC{}
+			uc := NewNonceReservationUsecase(t.TempDir(), time.Hour, rpc, log).(*nonceReservationUsecase)
+			for i, s := range test.steps {
+				rpc.pending = s.pending
+				if s.age > 0 {
+					age(t, uc, s.age)
+				}
+				r := domain.NonceRange{Sender: sender, Start: s.start, Count: s.count}
+				var err error
+				switch s.action {
+				case "reserve":
+					err = uc.ReserveNonces(r)
+				case "release":
+					err = uc.ReleaseNonces(r)
+				case "keep":
+					err = uc.KeepNonces(r)
+				case "refresh":
+					err = uc.RefreshNonces(r)
+				}
+				if s.errMessage != "" {
+					if err == nil || !strings.Contains(err.Error(), s.errMessage) {
+						t.Fatalf("step %v: expected error with %q, got %v", i+1, s.errMessage, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("step %v: %v", i+1, err)
+				}
+				next, err := uc.NextNonce(sender)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if next != s.next {
+					t.Errorf("step %v: expected next nonce %v, got %v", i+1, s.next, next)
+				}
+			}
+		})
+	}
+}
+
+// age is moving the reservations of the state file back in time
+func age(t *testing.T, uc *nonceReservationUsecase, age time.Duration) {
+	t.Helper()
+	err := uc.update(func(state map[string]*reservedNonce) error {
+		for _, reserved := range state {
+			reserved.Updated = time.Now().Add(-age)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentReservations(t *testing.T) {
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	dir := t.TempDir()
+	rpc := &fakeRPC{pending: 0}
+
+	var wg sync.WaitGroup
+	starts := make(chan uint64, 20)
+	for i := 0; i < cap(starts); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// every run has its own usecase like separate processes
+			uc := NewNonceReservationUsecase(dir, time.Hour, rpc, log)
+			for {
+				next, err := uc.NextNonce(sender)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				err = uc.ReserveNonces(domain.NonceRange{Sender: sender, Start: next, Count: 1})
+				if err == nil {
+					starts <- next
+					return
+				}
+				if !strings.Contains(err.Error(), "taken by another run") {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(starts)
+
+	reserved := map[uint64]bool{}
+	for start := range starts {
+		if reserved[start] {
+			t.Errorf("nonce %v is reserved twice", start)
+		}
+		reserved[start] = true
+	}
+	next, err := NewNonceReservationUsecase(dir, time.Hour, rpc, log).NextNonce(sender)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != uint64(cap(starts)) {
+		t.Errorf("expected next nonce %v, got %v", cap(starts), next)
+	}
+}
+
+func TestStaleLockIsRemoved(t *testing.T) {
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	dir := t.TempDir()
+	lockFile := filepath.Join(dir, lockFileName)
+	err := os.WriteFile(lockFile, []byte("1"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-staleLockAge - time.Minute)
+	err = os.Chtimes(lockFile, old, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := NewNonceReservationUsecase(dir, time.Hour, &fakeRPC{pending: 1}, log)
+	err = uc.ReserveNonces(domain.NonceRange{Sender: sender, Start: 1, Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lockFile); !os.IsNotExist(err) {
+		t.Errorf("expected lock file to be removed after reservation, got %v", err)
+	}
+}
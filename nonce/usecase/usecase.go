@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+type nonceUsecase struct {
+	logger logger.Logger
+	rpc    rpcClient.Client
+}
+
+// NewNonceUsecase create new nonce usecase
+func NewNonceUsecase(rpc rpcClient.Client, log logger.Logger) domain.NonceUseCase {
+	return &nonceUsecase{
+		rpc:    rpc,
+		logger: log,
+	}
+}
+
+// InspectNonces is finding nonces missing between pending nonce and queued transactions
+func (n *nonceUsecase) InspectNonces(addresses []string) ([]*domain.NonceReport, error) {
+	var reports []*domain.NonceReport
+	pool, err := n.rpc.GetTxPoolContent()
+	if err != nil {
+		return reports, err
+	}
+
+	// the node and the user may write addresses in different case or with other prefix
+	queued := map[string]map[uint64]bool{}
+	for address, byNonce := range pool.Queued {
+		sender := pkg.NormalizeAddress(address)
+		if queued[sender] == nil {
+			queued[sender] = map[uint64]bool{}
+		}
+		for key := range byNonce {
+			nonce, err := strconv.ParseUint(key, 10, 64)
+			if err != nil {
+				return reports, err
+			}
+			queued[sender][nonce] = true
+		}
+	}
+
+	for _, address := range addresses {
+		report := &domain.NonceReport{Address: address}
+		report.Latest, err = n.rpc.GetAccountNonce(address, "latest")
+		if err != nil {
+			return reports, err
+		}
+		report.Pending, err = n.rpc.GetAccountNonce(address, "pending")
+		if err != nil {
+			return reports, err
+		}
+
+		for nonce := range queued[pkg.NormalizeAddress(address)] {
+			report.Queued = append(report.Queued, nonce)
+		}
+		sort.Slice(report.Queued, func(i, j int) bool { return report.Queued[i] < report.Queued[j] })
+
+		next := report.Pending
+		for _, nonce := range report.Queued {
+			for ; next < nonce; next++ {
+				report.Gaps = append(report.Gaps, next)
+			}
+			if next == nonce {
+				next++
+			}
+		}
+		n.logger.Debugf("Sender %v: latest nonce %v, pending nonce %v, %v queued, %v gaps", address, report.Latest, report.Pending, len(report.Queued), len(report.Gaps))
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// GetGapFillingTxs is building self-sends at current network energy price
+func (n *nonceUsecase) GetGapFillingTxs(report *domain.NonceReport) (domain.TransactionList, error) {
+	var txs domain.TransactionList
+	if len(report.Gaps) == 0 {
+		return txs, nil
+	}
+	price, err := n.rpc.EstimateEnergyPrice()
+	if err != nil {
+		return txs, err
+	}
+	for _, nonce := range report.Gaps {
+		txs = append(txs, &domain.Transaction{
+			From:        report.Address,
+			To:          report.Address,
+			EnergyLimit: "21000",
+			EnergyPrice: strconv.FormatInt(price, 10),
+			Nonce:       strconv.FormatUint(nonce, 10),
+		})
+	}
+	return txs, nil
+}
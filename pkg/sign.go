@@ -0,0 +1,59 @@
+package pkg
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/common/hexutil"
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/go-core/v2/crypto"
+	"github.com/core-coin/go-core/v2/rlp"
+)
+
+// SignTx signs transaction for default network and returns it RLP encoded in hex
+func SignTx(tx *types.Transaction, key *crypto.PrivateKey) (string, error) {
+	signer := types.MakeSigner(big.NewInt(int64(common.DefaultNetworkID)))
+	signedTx, err := types.SignTx(tx, signer, key)
+	if err != nil {
+		return "", err
+	}
+
+	signedTxBytes, err := rlp.EncodeToBytes(signedTx)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(signedTxBytes), nil
+}
+
+// SignedTxHash returns hash of RLP encoded signed transaction in hex
+func SignedTxHash(signedTx string) (string, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(signedTx))
+	if err != nil {
+		return "", err
+	}
+	tx := new(types.Transaction)
+	err = rlp.DecodeBytes(raw, tx)
+	if err != nil {
+		return "", err
+	}
+	return tx.Hash().Hex(), nil
+}
+
+// SignedTxSender recovers sender of RLP encoded signed transaction in hex for default network
+func SignedTxSender(signedTx string) (string, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(signedTx))
+	if err != nil {
+		return "", err
+	}
+	tx := new(types.Transaction)
+	err = rlp.DecodeBytes(raw, tx)
+	if err != nil {
+		return "", err
+	}
+	from, err := types.Sender(types.MakeSigner(big.NewInt(int64(common.DefaultNetworkID))), tx)
+	if err != nil {
+		return "", err
+	}
+	return from.Hex(), nil
+}
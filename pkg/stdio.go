@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Stdio is the file name standing for standard input or standard output
+const Stdio = "-"
+
+var (
+	// InputFormat is the extension of data read from standard input, e.g. ".csv"
+	InputFormat = ".json"
+	// OutputFormat is the extension of data written to standard output
+	OutputFormat = ".json"
+)
+
+// InputExt returns lowercased extension of input file, InputFormat for standard input
+func InputExt(fileName string) string {
+	if fileName == Stdio {
+		return InputFormat
+	}
+	return strings.ToLower(filepath.Ext(fileName))
+}
+
+// OutputExt returns lowercased extension of output file, OutputFormat for standard output
+func OutputExt(fileName string) string {
+	if fileName == Stdio {
+		return OutputFormat
+	}
+	return strings.ToLower(filepath.Ext(fileName))
+}
+
+// OpenInput opens file for reading, standard input for Stdio
+func OpenInput(fileName string) (io.ReadCloser, error) {
+	if fileName == Stdio {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(fileName)
+}
+
+// ReadInput reads whole file, standard input for Stdio
+func ReadInput(fileName string) ([]byte, error) {
+	if fileName == Stdio {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(fileName)
+}
+
+// OpenOutput opens file for writing with extra flag like os.O_TRUNC or os.O_APPEND, standard output for Stdio
+func OpenOutput(fileName string, flag int) (io.WriteCloser, error) {
+	if fileName == Stdio {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|flag, 0644)
+}
+
+// WriteOutput writes data to file, standard output for Stdio
+func WriteOutput(fileName string, data []byte) error {
+	if fileName == Stdio {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(fileName, data, 0644)
+}
+
+// nopWriteCloser keeps standard output open
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -1,5 +1,36 @@
 package pkg
 
-import "github.com/core-coin/go-core/v2/common/math"
+import (
+	"math/big"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/common/math"
+)
 
 var Core = math.BigPow(10, 18)
+
+// FormatOre formats amount in ore as exact decimal amount in cores
+func FormatOre(ore *big.Int) string {
+	s := new(big.Rat).SetFrac(ore, Core).FloatString(18)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// NormalizeAddress returns address in canonical form or lowercased as is if it cannot be parsed
+func NormalizeAddress(address string) string {
+	addr, err := common.HexToAddress(address)
+	if err != nil {
+		return strings.ToLower(address)
+	}
+	return addr.Hex()
+}
+
+// IsJSONLines reports whether extension stands for one JSON value per line
+func IsJSONLines(ext string) bool {
+	switch ext {
+	case ".jsonl", ".ndjson":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+type planUsecase struct {
+	logger logger.Logger
+}
+
+// NewPlanUsecase create new distribution plan usecase
+func NewPlanUsecase(log logger.Logger) domain.PlanUseCase {
+	return &planUsecase{
+		logger: log,
+	}
+}
+
+// GetRecipientsFromFile is choosing format by file extension, CSV file must have titles
+func (p *planUsecase) GetRecipientsFromFile(fileName string) ([]*domain.Recipient, error) {
+	var recipients []*domain.Recipient
+	switch pkg.InputExt(fileName) {
+	case ".json":
+		data, err := pkg.ReadInput(fileName)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &recipients)
+		if err != nil {
+			return nil, err
+		}
+	case ".csv":
+		in, err := pkg.OpenInput(fileName)
+		if err != nil {
+			return nil, err
+		}
+		defer in.Close()
+		err = gocsv.UnmarshalCSV(gocsv.DefaultCSVReader(in), &recipients)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unsupported file extension")
+	}
+	return recipients, nil
+}
+
+// payee is a recipient taking part in distribution
+type payee struct {
+	address string
+	weight  *big.Rat
+	payout  *big.Int
+	capped  bool
+}
+
+// Plan is computing payouts in ore, flooring every share
+func (p *planUsecase) Plan(total domain.Amount, recipients []*domain.Recipient, options *domain.PlanOptions) (domain.TransactionList, error) {
+	if total.Sign() <= 0 {
+		return nil, errors.New("total must be positive")
+	}
+	if options.MaxPayout.Sign() > 0 && options.MinPayout.Cmp(options.MaxPayout) > 0 {
+		return nil, errors.New("minimum payout is above maximum payout")
+	}
+
+	var payees []*payee
+	rows := map[string]int{}
+	for i, recipient := range recipients {
+		address := pkg.NormalizeAddress(recipient.Address)
+		if row, ok := rows[address]; ok {
+			return nil, fmt.Errorf("row %v: recipient %v is already listed in row %v", i+1, recipient.Address, row)
+		}
+		rows[address] = i + 1
+		weight, err := parseWeight(recipient.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("row %v: %v", i+1, err)
+		}
+		if weight.Sign() == 0 {
+			continue
+		}
+		payees = append(payees, &payee{address: recipient.Address, weight: weight})
+	}
+
+	for {
+		if len(payees) == 0 {
+			return nil, errors.New("no recipient gets a payout")
+		}
+		remaining := total.Ore()
+		weights := new(big.Rat)
+		for _, payee := range payees {
+			if payee.capped {
+				remaining.Sub(remaining, payee.payout)
+			} else {
+				weights.Add(weights, payee.weight)
+			}
+		}
+		if weights.Sign() == 0 {
+			return nil, fmt.Errorf("all recipients are capped at %v, %v cannot be distributed", options.MaxPayout, pkg.FormatOre(remaining))
+		}
+
+		changed := false
+		for _, payee := range payees {
+			if payee.capped {
+				continue
+			}
+			share := new(big.Rat).Mul(new(big.Rat).SetInt(remaining), payee.weight)
+			share.Quo(share, weights)
+			payee.payout = new(big.Int).Quo(share.Num(), share.Denom())
+			if options.MaxPayout.Sign() > 0 && payee.payout.Cmp(options.MaxPayout.Ore()) > 0 {
+				payee.payout = options.MaxPayout.Ore()
+				payee.capped = true
+				changed = true
+			}
+		}
+		if changed {
+			continue
+		}
+
+		// drop recipients below minimum and distribute their shares among the rest
+		if options.MinPayout.Sign() > 0 {
+			var kept []*payee
+			for _, payee := range payees {
+				if payee.payout.Cmp(options.MinPayout.Ore()) < 0 {
+					p.logger.Infof("Recipient %v gets nothing, payout %v is below minimum %v", payee.address, pkg.FormatOre(payee.payout), options.MinPayout)
+					continue
+				}
+				kept = append(kept, payee)
+			}
+			if len(kept) != len(payees) {
+				payees = kept
+				for _, payee := range payees {
+					payee.capped = false
+				}
+				continue
+			}
+		}
+		break
+	}
+
+	distributed := new(big.Int)
+	for _, payee := range payees {
+		distributed.Add(distributed, payee.payout)
+	}
+	remainder := new(big.Int).Sub(total.Ore(), distributed)
+	if remainder.Sign() > 0 {
+		remainderTo := payees[0]
+		if options.RemainderTo != "" {
+			remainderTo = nil
+			for _, payee := range payees {
+				if pkg.NormalizeAddress(payee.address) == pkg.NormalizeAddress(options.RemainderTo) {
+					remainderTo = payee
+					break
+				}
+			}
+			if remainderTo == nil {
+				return nil, fmt.Errorf("remainder recipient %v gets no payout", options.RemainderTo)
+			}
+		}
+		// the remainder must not lift a payout above maximum, another recipient takes it then
+		fits := func(payee *payee) bool {
+			return options.MaxPayout.Sign() <= 0 || new(big.Int).Add(payee.payout, remainder).Cmp(options.MaxPayout.Ore()) <= 0
+		}
+		if !fits(remainderTo) {
+			chosen := remainderTo
+			remainderTo = nil
+			for _, payee := range payees {
+				if fits(payee) {
+					remainderTo = payee
+					break
+				}
+			}
+			if remainderTo == nil {
+				return nil, fmt.Errorf("rounding remainder %v cannot be distributed, every payout would get above maximum %v", pkg.FormatOre(remainder), options.MaxPayout)
+			}
+			p.logger.Infof("Recipient %v is capped at maximum %v, rounding remainder goes to another recipient", chosen.address, options.MaxPayout)
+		}
+		remainderTo.payout.Add(remainderTo.payout, remainder)
+		p.logger.Infof("Rounding remainder %v goes to %v", pkg.FormatOre(remainder), remainderTo.address)
+	}
+
+	txs := make(domain.TransactionList, 0, len(payees))
+	for _, payee := range payees {
+		txs = append(txs, &domain.Transaction{
+			From:   options.From,
+			To:     payee.address,
+			Amount: domain.NewAmount(payee.payout),
+		})
+	}
+	return txs, nil
+}
+
+// parseWeight parses non-negative decimal weight, exponent notation is allowed
+func parseWeight(value string) (*big.Rat, error) {
+	value = strings.TrimSpace(value)
+	// big.Rat also reads fractions like 1/3 and prefixes like 0x, they are not decimal weights
+	if value == "" || strings.Trim(value, "0123456789+-.eE") != "" {
+		return nil, fmt.Errorf("bad weight %q", value)
+	}
+	weight, ok := new(big.Rat).SetString(value)
+	if !ok || weight.Sign() < 0 {
+		return nil, fmt.Errorf("bad weight %q", value)
+	}
+	return weight, nil
+}
@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/logger/zap"
+)
+
+const (
+	alice = "cb000000000000000000000000000000000000000001"
+	bob   = "cb000000000000000000000000000000000000000002"
+	carol = "cb000000000000000000000000000000000000000003"
+)
+
+func ore(value int64) domain.Amount {
+	return domain.NewAmount(big.NewInt(value))
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name       string
+		total      int64
+		weights    []string
+		addresses  []string
+		options    domain.PlanOptions
+		payouts    map[string]int64
+		errMessage string
+	}{
+		{
+			name:    "pro-rata split",
+			total:   100,
+			weights: []string{"1", "1", "2"},
+			payouts: map[string]int64{alice: 25, bob: 25, carol: 50},
+		},
+		{
+			name:    "fractional weights",
+			total:   100,
+			weights: []string{"0.5", "1.5", "0"},
+			payouts: map[string]int64{alice: 25, bob: 75},
+		},
+		{
+			name:    "remainder goes to the first recipient",
+			total:   10,
+			weights: []string{"1", "1", "1"},
+			payouts: map[string]int64{alice: 4, bob: 3, carol: 3},
+		},
+		{
+			name:    "remainder goes to the chosen recipient",
+			total:   11,
+			weights: []string{"1", "1", "1"},
+			options: domain.PlanOptions{RemainderTo: carol},
+			payouts: map[string]int64{alice: 3, bob: 3, carol: 5},
+		},
+		{
+			name:       "remainder recipient without payout",
+			total:      11,
+			weights:    []string{"1", "1", "0"},
+			options:    domain.PlanOptions{RemainderTo: carol},
+			errMessage: "gets no payout",
+		},
+		{
+			name:    "recipients below minimum are dropped",
+			total:   100,
+			weights: []string{"1", "9", "10"},
+			options: domain.PlanOptions{MinPayout: ore(20)},
+			payouts: map[string]int64{bob: 48, carol: 52},
+		},
+		{
+			name:    "payouts above maximum are capped",
+			total:   100,
+			weights: []string{"1", "1", "2"},
+			options: domain.PlanOptions{MaxPayout: ore(40)},
+			payouts: map[string]int64{alice: 30, bob: 30, carol: 40},
+		},
+		{
+			name:    "remainder skips capped recipient",
+			total:   101,
+			weights: []string{"3", "1", "1"},
+			options: domain.PlanOptions{MaxPayout: ore(50)},
+			payouts: map[string]int64{alice: 50, bob: 26, carol: 25},
+		},
+		{
+			name:       "remainder above every maximum",
+			total:      10,
+			weights:    []string{"1", "1", "1"},
+			options:    domain.PlanOptions{MaxPayout: ore(3)},
+			errMessage: "cannot be distributed",
+		},
+		{
+			name:       "everybody capped",
+			total:      100,
+			weights:    []string{"1", "1"},
+			options:    domain.PlanOptions{MaxPayout: ore(40)},
+			errMessage: "all recipients are capped",
+		},
+		{
+			name:       "minimum above maximum",
+			total:      100,
+			weights:    []string{"1"},
+			options:    domain.PlanOptions{MinPayout: ore(50), MaxPayout: ore(40)},
+			errMessage: "minimum payout is above maximum payout",
+		},
+		{
+			name:       "negative weight",
+			total:      100,
+			weights:    []string{"1", "-1"},
+			errMessage: "bad weight",
+		},
+		{
+			name:       "fraction weight",
+			total:      100,
+			weights:    []string{"1", "1/3"},
+			errMessage: "bad weight",
+		},
+		{
+			name:       "hexadecimal weight",
+			total:      100,
+			weights:    []string{"0x10", "1"},
+			errMessage: "bad weight",
+		},
+		{
+			name:       "empty weight",
+			total:      100,
+			weights:    []string{"1", ""},
+			errMessage: "bad weight",
+		},
+		{
+			name:    "exponent weight",
+			total:   100,
+			weights: []string{"1e0", "3"},
+			payouts: map[string]int64{alice: 25, bob: 75},
+		},
+		{
+			name:       "duplicate recipient",
+			total:      100,
+			weights:    []string{"1", "1", "1"},
+			addresses:  []string{alice, bob, "CB000000000000000000000000000000000000000001"},
+			errMessage: "already listed in row 1",
+		},
+	}
+
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	uc := NewPlanUsecase(log)
+	addresses := []string{alice, bob, carol}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			names := addresses
+			if test.addresses != nil {
+				names = test.addresses
+			}
+			var recipients []*domain.Recipient
+			for i, weight := range test.weights {
+				recipients = append(recipients, &domain.Recipient{Address: names[i], Weight: weight})
+			}
+			options := test.options
+			txs, err := uc.Plan(ore(test.total), recipients, &options)
+			if test.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errMessage) {
+					t.Fatalf("expected error with %q, got %v", test.errMessage, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(txs) != len(test.payouts) {
+				t.Fatalf("expected %v payouts, got %v", len(test.payouts), len(txs))
+			}
+			for _, tx := range txs {
+				expected, ok := test.payouts[tx.To]
+				if !ok {
+					t.Fatalf("unexpected payout to %v", tx.To)
+				}
+				if tx.Amount.Ore().Int64() != expected {
+					t.Errorf("payout to %v is %v ore, expected %v", tx.To, tx.Amount.Ore(), expected)
+				}
+			}
+		})
+	}
+}
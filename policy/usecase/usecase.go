@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/common"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+type policyUsecase struct {
+	policy *domain.Policy
+	logger logger.Logger
+	rpc    rpcClient.Client
+
+	// totals of transactions checked so far, a batch may be checked in chunks
+	rows         int
+	batchTotal   *big.Int
+	senderTotals map[string]*big.Int
+	contracts    map[string]bool
+}
+
+// NewPolicyUsecase create new spending policy usecase
+func NewPolicyUsecase(policy *domain.Policy, rpc rpcClient.Client, log logger.Logger) domain.PolicyUseCase {
+	return &policyUsecase{
+		policy:       policy,
+		rpc:          rpc,
+		logger:       log,
+		batchTotal:   new(big.Int),
+		senderTotals: map[string]*big.Int{},
+		contracts:    map[string]bool{},
+	}
+}
+
+// GetPolicyFromFile is loading spending policy from json file
+func GetPolicyFromFile(fileName string) (*domain.Policy, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	policy := &domain.Policy{}
+	err = json.Unmarshal(data, policy)
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Reset is starting totals and row numbers of a new batch
+func (p *policyUsecase) Reset() {
+	p.rows = 0
+	p.batchTotal = new(big.Int)
+	p.senderTotals = map[string]*big.Int{}
+}
+
+// Check is collecting violations of all rules for all rows.
+// Totals and row numbers continue from previous calls, so chunks of one batch are checked as a whole.
+func (p *policyUsecase) Check(txs domain.TransactionList) error {
+	var violations []string
+	violate := func(row int, rule string, format string, args ...interface{}) {
+		violations = append(violations, fmt.Sprintf("row %v: %v: %v", row, rule, fmt.Sprintf(format, args...)))
+	}
+
+	allowlist, err := addressSet(p.policy.Allowlist)
+	if err != nil {
+		return fmt.Errorf("bad address in policy allowlist: %v", err)
+	}
+	denylist, err := addressSet(p.policy.Denylist)
+	if err != nil {
+		return fmt.Errorf("bad address in policy denylist: %v", err)
+	}
+	var maxEnergyPrice *big.Int
+	if p.policy.MaxEnergyPrice != "" {
+		var ok bool
+		maxEnergyPrice, ok = new(big.Int).SetString(p.policy.MaxEnergyPrice, 10)
+		if !ok {
+			return errors.New("max energy price in policy has bad number")
+		}
+	}
+
+	batchTotal := p.batchTotal
+	senderTotals := p.senderTotals
+	contracts := p.contracts
+
+	for i, tx := range txs {
+		row := p.rows + i + 1
+		amount := tx.Amount.Ore()
+		from := pkg.NormalizeAddress(tx.From)
+		to := pkg.NormalizeAddress(tx.To)
+
+		if p.policy.MaxAmount.Sign() > 0 && tx.Amount.Cmp(p.policy.MaxAmount) > 0 {
+			violate(row, "max_amount", "amount %v is above %v", tx.Amount, p.policy.MaxAmount)
+		}
+		if !p.policy.AllowZeroValue && amount.Sign() == 0 {
+			violate(row, "allow_zero_value", "zero-value transaction")
+		}
+		if !p.policy.AllowSelfSend && from != "" && from == to {
+			violate(row, "allow_self_send", "sender %v sends to itself", tx.From)
+		}
+		if len(allowlist) > 0 && !allowlist[to] {
+			violate(row, "allowlist", "recipient %v is not in allowlist", tx.To)
+		}
+		if denylist[to] {
+			violate(row, "denylist", "recipient %v is in denylist", tx.To)
+		}
+		if maxEnergyPrice != nil {
+			price, ok := new(big.Int).SetString(tx.EnergyPrice, 10)
+			if !ok {
+				violate(row, "max_energy_price", "energy price %q has bad number", tx.EnergyPrice)
+			} else if price.Cmp(maxEnergyPrice) > 0 {
+				violate(row, "max_energy_price", "energy price %v is above %v", price, maxEnergyPrice)
+			}
+		}
+		if !p.policy.AllowContracts {
+			isContract, ok := contracts[to]
+			if !ok {
+				code, err := p.rpc.GetCode(tx.To, "latest")
+				if err != nil {
+					return fmt.Errorf("cannot check whether recipient %v is a contract: %v", tx.To, err)
+				}
+				isContract = len(code) > 0
+				contracts[to] = isContract
+			}
+			if isContract {
+				violate(row, "allow_contracts", "recipient %v is a contract", tx.To)
+			}
+		}
+
+		batchTotal.Add(batchTotal, amount)
+		if _, ok := senderTotals[from]; !ok {
+			senderTotals[from] = new(big.Int)
+		}
+		senderTotals[from].Add(senderTotals[from], amount)
+		if p.policy.MaxSenderTotal.Sign() > 0 && senderTotals[from].Cmp(p.policy.MaxSenderTotal.Ore()) > 0 {
+			violate(row, "max_sender_total", "total of sender %v exceeds %v", tx.From, p.policy.MaxSenderTotal)
+		}
+	}
+	p.rows += len(txs)
+	if p.policy.MaxBatchTotal.Sign() > 0 && batchTotal.Cmp(p.policy.MaxBatchTotal.Ore()) > 0 {
+		violations = append(violations, fmt.Sprintf("batch: max_batch_total: total %v is above %v", pkg.FormatOre(batchTotal), p.policy.MaxBatchTotal))
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("spending policy is violated:\n%v", strings.Join(violations, "\n"))
+	}
+	p.logger.Debugf("%v transactions comply with spending policy", len(txs))
+	return nil
+}
+
+// addressSet is parsing addresses to a set of normalized addresses
+func addressSet(addresses []string) (map[string]bool, error) {
+	set := map[string]bool{}
+	for _, address := range addresses {
+		addr, err := common.HexToAddress(address)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", address, err)
+		}
+		set[addr.Hex()] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger/zap"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const (
+	alice = "cb94495583d6a5be74918e6324a0a08170c60a72c01e"
+	bob   = "cb215f4de417f29cd80023604de3deca704bc66f6c0e"
+)
+
+// fakeRPC is answering code of contracts, other calls are not used by policy
+type fakeRPC struct {
+	rpcClient.Client
+	contracts map[string]bool
+	calls     int
+}
+
+func (f *fakeRPC) GetCode(account, status string) ([]byte, error) {
+	f.calls++
+	if f.contracts[pkg.NormalizeAddress(account)] {
+		return []byte{0x60}, nil
+	}
+	return nil, nil
+}
+
+func ore(value int64) domain.Amount {
+	return domain.NewAmount(big.NewInt(value))
+}
+
+func pay(from, to string, value int64, price string) *domain.Transaction {
+	return &domain.Transaction{From: from, To: to, Amount: ore(value), EnergyPrice: price}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    domain.Policy
+		contracts []string
+		txs       domain.TransactionList
+		// violations are written as row: rule, in the order they are listed
+		violations []string
+		errMessage string
+	}{
+		{
+			name:   "compliant batch",
+			policy: domain.Policy{MaxAmount: ore(10), MaxBatchTotal: ore(20), MaxSenderTotal: ore(20), MaxEnergyPrice: "5"},
+			txs:    domain.TransactionList{pay(alice, bob, 10, "5"), pay(alice, bob, 10, "1")},
+		},
+		{
+			name:       "amount above maximum",
+			policy:     domain.Policy{MaxAmount: ore(10)},
+			txs:        domain.TransactionList{pay(alice, bob, 10, ""), pay(alice, bob, 11, "")},
+			violations: []string{"row 2: max_amount"},
+		},
+		{
+			name:       "zero value and self send",
+			policy:     domain.Policy{},
+			txs:        domain.TransactionList{pay(alice, bob, 0, ""), pay(alice, "CB94495583D6A5BE74918E6324A0A08170C60A72C01E", 1, "")},
+			violations: []string{"row 1: allow_zero_value", "row 2: allow_self_send"},
+		},
+		{
+			name:   "zero value and self send allowed",
+			policy: domain.Policy{AllowZeroValue: true, AllowSelfSend: true},
+			txs:    domain.TransactionList{pay(alice, alice, 0, "")},
+		},
+		{
+			name:       "allowlist and denylist",
+			policy:     domain.Policy{Allowlist: []string{bob}, Denylist: []string{bob}},
+			txs:        domain.TransactionList{pay(bob, alice, 1, ""), pay(alice, bob, 1, "")},
+			violations: []string{"row 1: allowlist", "row 2: denylist"},
+		},
+		{
+			name:       "energy price above maximum or not a number",
+			policy:     domain.Policy{MaxEnergyPrice: "5"},
+			txs:        domain.TransactionList{pay(alice, bob, 1, "6"), pay(alice, bob, 1, "0x1"), pay(alice, bob, 1, "5")},
+			violations: []string{"row 1: max_energy_price", "row 2: max_energy_price"},
+		},
+		{
+			name:       "contract recipient",
+			policy:     domain.Policy{},
+			contracts:  []string{bob},
+			txs:        domain.TransactionList{pay(alice, bob, 1, ""), pay(bob, alice, 1, ""), pay(alice, bob, 1, "")},
+			violations: []string{"row 1: allow_contracts", "row 3: allow_contracts"},
+		},
+		{
+			name:       "sender and batch totals",
+			policy:     domain.Policy{MaxSenderTotal: ore(10), MaxBatchTotal: ore(15)},
+			txs:        domain.TransactionList{pay(alice, bob, 6, ""), pay(bob, alice, 6, ""), pay(alice, bob, 6, "")},
+			violations: []string{"row 3: max_sender_total", "batch: max_batch_total"},
+		},
+		{
+			name:       "every violation of a row is listed",
+			policy:     domain.Policy{MaxAmount: ore(1), Denylist: []string{alice}},
+			txs:        domain.TransactionList{pay(alice, alice, 2, "")},
+			violations: []string{"row 1: max_amount", "row 1: allow_self_send", "row 1: denylist"},
+		},
+		{
+			name:       "bad address in allowlist",
+			policy:     domain.Policy{Allowlist: []string{"cb01"}},
+			txs:        domain.TransactionList{pay(alice, bob, 1, "")},
+			errMessage: "bad address in policy allowlist",
+		},
+	}
+
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rpc := &fakeRPC{contracts: map[string]bool{}}
+			for _, contract := range test.contracts {
+				rpc.contracts[pkg.NormalizeAddress(contract)] = true
+			}
+			policy := test.policy
+			err := NewPolicyUsecase(&policy, rpc, log).Check(test.txs)
+			if test.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errMessage) {
+					t.Fatalf("expected error with %q, got %v", test.errMessage, err)
+				}
+				return
+			}
+			if len(test.violations) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected violations %v, got none", test.violations)
+			}
+			lines := strings.Split(err.Error(), "\n")[1:]
+			if len(lines) != len(test.violations) {
+				t.Fatalf("expected violations %v, got %v", test.violations, lines)
+			}
+			for i, violation := range test.violations {
+				if !strings.HasPrefix(lines[i], violation+": ") {
+					t.Errorf("expected violation %q, got %q", violation, lines[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckInChunks(t *testing.T) {
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	rpc := &fakeRPC{contracts: map[string]bool{}}
+	uc := NewPolicyUsecase(&domain.Policy{MaxBatchTotal: ore(10)}, rpc, log)
+	err := uc.Check(domain.TransactionList{pay(alice, bob, 6, "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = uc.Check(domain.TransactionList{pay(alice, bob, 1, ""), pay(alice, bob, 4, "")})
+	if err == nil || !strings.Contains(err.Error(), "batch: max_batch_total: total") {
+		t.Fatalf("expected batch total over chunks to be refused, got %v", err)
+	}
+	if rpc.calls != 1 {
+		t.Errorf("expected code of the recipient to be asked once, asked %v times", rpc.calls)
+	}
+
+	uc.Reset()
+	err = uc.Check(domain.TransactionList{pay(alice, bob, 0, "")})
+	if err == nil || !strings.HasPrefix(strings.Split(err.Error(), "\n")[1], "row 1: ") {
+		t.Fatalf("expected row numbers to start again after reset, got %v", err)
+	}
+}
@@ -0,0 +1,380 @@
+package usecase
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/common/hexutil"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+type reconcileUsecase struct {
+	logger logger.Logger
+	rpc    rpcClient.Client
+}
+
+// NewReconcileUsecase create new reconcile usecase
+func NewReconcileUsecase(rpc rpcClient.Client, log logger.Logger) domain.ReconcileUseCase {
+	return &reconcileUsecase{
+		rpc:    rpc,
+		logger: log,
+	}
+}
+
+// chainTx is a listed transaction as the node knows it, row is the matched 1-based row, 0 if none
+type chainTx struct {
+	tx      *rpcClient.Transaction
+	hash    string
+	from    string
+	payment payment
+	pending bool
+	failed  bool
+	row     int
+}
+
+// payment is a normalized recipient and value in ore, transactions and rows are indexed by it
+type payment struct {
+	to    string
+	value string
+}
+
+// fileRow is a 1-based row of the file with normalized sender and payment, nonce is set if the row has a valid one
+type fileRow struct {
+	index   int
+	tx      *domain.Transaction
+	from    string
+	payment payment
+	nonce   *uint64
+}
+
+// senderNonce identifies a transaction of a sender
+type senderNonce struct {
+	sender string
+	nonce  uint64
+}
+
+// nonceRange is the range of listed nonces of a sender and the blocks they were mined in,
+// skip is set if the range is not mined yet
+type nonceRange struct {
+	minNonce, maxNonce uint64
+	minBlock, maxBlock uint64
+	skip               bool
+}
+
+// Reconcile is matching rows by recipient, value and, if the row has them, sender and nonce, so the order
+// of the tx IDs file does not matter. A row with sender and nonce whose transaction differs is a mismatch.
+func (r *reconcileUsecase) Reconcile(txs domain.TransactionList, txIDs []string) ([]*domain.Discrepancy, error) {
+	var discrepancies []*domain.Discrepancy
+	add := func(kind string, row int, hash, detail string, args ...interface{}) {
+		discrepancies = append(discrepancies, &domain.Discrepancy{Kind: kind, Row: row, Hash: hash, Detail: fmt.Sprintf(detail, args...)})
+	}
+
+	listed := map[string]bool{}
+	var found []*chainTx
+	for _, hash := range txIDs {
+		key := strings.ToLower(strings.TrimSpace(hash))
+		if listed[key] {
+			add(domain.DiscrepancyDuplicated, 0, hash, "transaction is listed more than once")
+			continue
+		}
+		listed[key] = true
+		tx, err := r.rpc.GetTransactionByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+		if tx == nil {
+			add(domain.DiscrepancyMissing, 0, hash, "transaction is not known to the node")
+			continue
+		}
+		found = append(found, &chainTx{
+			tx:      tx,
+			hash:    hash,
+			from:    pkg.NormalizeAddress(tx.From),
+			payment: txPayment(tx),
+			pending: tx.BlockNumber == nil,
+		})
+		if tx.BlockNumber == nil {
+			continue
+		}
+		receipt, err := r.rpc.GetTransactionReceipt(hash)
+		if err != nil {
+			return nil, err
+		}
+		found[len(found)-1].failed = receipt != nil && receipt.Status == 0
+	}
+	r.logger.Debugf("Found %v of %v listed transactions", len(found), len(txIDs))
+
+	byPayment := map[payment][]*chainTx{}
+	byNonce := map[senderNonce][]*chainTx{}
+	for _, c := range found {
+		byPayment[c.payment] = append(byPayment[c.payment], c)
+		key := senderNonce{c.from, uint64(c.tx.Nonce)}
+		byNonce[key] = append(byNonce[key], c)
+	}
+	rows := make([]*fileRow, len(txs))
+	rowsByPayment := map[payment][]*fileRow{}
+	for i, tx := range txs {
+		rows[i] = newFileRow(i+1, tx)
+		rowsByPayment[rows[i].payment] = append(rowsByPayment[rows[i].payment], rows[i])
+	}
+
+	matched := make([]bool, len(txs))
+	for i, row := range rows {
+		for _, c := range byPayment[row.payment] {
+			if c.row != 0 || !row.pays(c, true) {
+				continue
+			}
+			c.row = i + 1
+			matched[i] = true
+			if c.pending {
+				add(domain.DiscrepancyPending, c.row, c.hash, "transaction is not mined yet")
+			} else if c.failed {
+				add(domain.DiscrepancyFailed, c.row, c.hash, "transaction was mined in block %v with failed status", c.tx.BlockNumber.ToInt())
+			}
+			break
+		}
+	}
+	for i, row := range rows {
+		if matched[i] {
+			continue
+		}
+		if c := findBySenderNonce(byNonce, row); c != nil {
+			c.row = i + 1
+			add(domain.DiscrepancyMismatch, c.row, c.hash, "%v", describeMismatch(row, c))
+			continue
+		}
+		add(domain.DiscrepancyMissing, i+1, "", "no listed transaction pays %v to %v", row.tx.Amount, row.tx.To)
+	}
+	for _, c := range found {
+		if c.row != 0 {
+			continue
+		}
+		if row := findPaidRow(rowsByPayment[c.payment], c); row != 0 {
+			add(domain.DiscrepancyDuplicated, row, c.hash, "transaction pays the row again with nonce %v", uint64(c.tx.Nonce))
+			continue
+		}
+		add(domain.DiscrepancyUnexpected, 0, c.hash, "transaction from %v with nonce %v pays %v to %v, it is not in the file",
+			c.tx.From, uint64(c.tx.Nonce), domain.NewAmount(c.tx.Value.ToInt()), recipient(c.tx))
+	}
+
+	unlisted, err := r.findUnlisted(found, rows, listed)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range unlisted {
+		add(domain.DiscrepancyUnexpected, 0, tx.Hash, "transaction from %v with nonce %v pays %v to %v, it is not listed",
+			tx.From, uint64(tx.Nonce), domain.NewAmount(tx.Value.ToInt()), recipient(tx))
+	}
+	return discrepancies, nil
+}
+
+// findUnlisted is scanning blocks for other transactions of senders in their nonce range, the range spans nonces
+// of listed transactions and of rows with sender and nonce. As nonces are mined in order, blocks are scanned from
+// the block where the first nonce of the range was mined to the block where the last one was, so replacements
+// of listed transactions are found even if they were mined later than every listed one.
+func (r *reconcileUsecase) findUnlisted(found []*chainTx, rows []*fileRow, listed map[string]bool) ([]*rpcClient.Transaction, error) {
+	ranges := map[string]*nonceRange{}
+	add := func(sender string, nonce uint64) {
+		if nr, ok := ranges[sender]; ok {
+			nr.extend(nonce)
+		} else {
+			ranges[sender] = &nonceRange{minNonce: nonce, maxNonce: nonce}
+		}
+	}
+	for _, c := range found {
+		add(c.from, uint64(c.tx.Nonce))
+	}
+	for _, row := range rows {
+		if row.from != "" && row.nonce != nil {
+			add(row.from, *row.nonce)
+		}
+	}
+	if len(ranges) == 0 {
+		return nil, nil
+	}
+
+	head, err := r.rpc.GetBlockNumber()
+	if err != nil {
+		return nil, err
+	}
+	var firstBlock, lastBlock uint64
+	scanned := false
+	for sender, nr := range ranges {
+		first, mined, err := r.minedIn(sender, nr.minNonce, head)
+		if err != nil {
+			return nil, err
+		}
+		if !mined {
+			nr.skip = true
+			continue
+		}
+		last, mined, err := r.minedIn(sender, nr.maxNonce, head)
+		if err != nil {
+			return nil, err
+		}
+		if !mined {
+			last = head
+		}
+		nr.minBlock, nr.maxBlock = first, last
+		if !scanned || first < firstBlock {
+			firstBlock = first
+		}
+		if !scanned || last > lastBlock {
+			lastBlock = last
+		}
+		scanned = true
+	}
+	if !scanned {
+		return nil, nil
+	}
+	r.logger.Debugf("Scanning blocks %v-%v for unlisted transactions", firstBlock, lastBlock)
+
+	var unlisted []*rpcClient.Transaction
+	for number := firstBlock; number <= lastBlock; number++ {
+		block, err := r.rpc.GetBlockByNumber(number)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("block %v is not found", number)
+		}
+		for _, tx := range block.Transactions {
+			nr, ok := ranges[pkg.NormalizeAddress(tx.From)]
+			if !ok || nr.skip || listed[strings.ToLower(tx.Hash)] {
+				continue
+			}
+			nonce := uint64(tx.Nonce)
+			if nonce >= nr.minNonce && nonce <= nr.maxNonce && number >= nr.minBlock && number <= nr.maxBlock {
+				unlisted = append(unlisted, tx)
+			}
+		}
+	}
+	return unlisted, nil
+}
+
+// minedIn is finding the first block up to head after which the transaction count of sender is above nonce,
+// which is the block the nonce was mined in. Returns false if the nonce is not mined by head.
+func (r *reconcileUsecase) minedIn(sender string, nonce, head uint64) (uint64, bool, error) {
+	count, err := r.rpc.GetAccountNonce(sender, hexutil.EncodeUint64(head))
+	if err != nil {
+		return 0, false, err
+	}
+	if count <= nonce {
+		return 0, false, nil
+	}
+	low, high := uint64(0), head
+	for low < high {
+		middle := low + (high-low)/2
+		count, err := r.rpc.GetAccountNonce(sender, hexutil.EncodeUint64(middle))
+		if err != nil {
+			return 0, false, err
+		}
+		if count > nonce {
+			high = middle
+		} else {
+			low = middle + 1
+		}
+	}
+	return low, true, nil
+}
+
+// extend is widening the range to include nonce
+func (nr *nonceRange) extend(nonce uint64) {
+	if nonce < nr.minNonce {
+		nr.minNonce = nonce
+	}
+	if nonce > nr.maxNonce {
+		nr.maxNonce = nonce
+	}
+}
+
+// newFileRow is normalizing addresses and amount of the row once for matching
+func newFileRow(index int, tx *domain.Transaction) *fileRow {
+	row := &fileRow{
+		index:   index,
+		tx:      tx,
+		payment: payment{to: pkg.NormalizeAddress(tx.To), value: tx.Amount.Ore().String()},
+	}
+	if tx.From != "" {
+		row.from = pkg.NormalizeAddress(tx.From)
+	}
+	if nonce, err := strconv.ParseUint(tx.Nonce, 10, 64); err == nil {
+		row.nonce = &nonce
+	}
+	return row
+}
+
+// txPayment is normalizing recipient and value of transaction, value is empty if the node did not return it
+func txPayment(tx *rpcClient.Transaction) payment {
+	p := payment{to: pkg.NormalizeAddress(recipient(tx))}
+	if tx.Value != nil {
+		p.value = tx.Value.ToInt().String()
+	}
+	return p
+}
+
+// pays is checking whether transaction pays the row, sender and nonce are compared if the row has them
+func (row *fileRow) pays(c *chainTx, withNonce bool) bool {
+	if row.payment != c.payment || c.payment.value == "" {
+		return false
+	}
+	if row.from != "" && row.from != c.from {
+		return false
+	}
+	if withNonce && row.tx.Nonce != "" && (row.nonce == nil || *row.nonce != uint64(c.tx.Nonce)) {
+		return false
+	}
+	return true
+}
+
+// findBySenderNonce returns unmatched transaction with sender and nonce of the row, nil if the row has none of them
+func findBySenderNonce(byNonce map[senderNonce][]*chainTx, row *fileRow) *chainTx {
+	if row.from == "" || row.nonce == nil {
+		return nil
+	}
+	for _, c := range byNonce[senderNonce{row.from, *row.nonce}] {
+		if c.row == 0 {
+			return c
+		}
+	}
+	return nil
+}
+
+// findPaidRow returns 1-based row of rows with the payment of transaction which it pays regardless of nonce, 0 if none
+func findPaidRow(rows []*fileRow, c *chainTx) int {
+	for _, row := range rows {
+		if row.pays(c, false) {
+			return row.index
+		}
+	}
+	return 0
+}
+
+// describeMismatch lists fields of transaction which differ from the row
+func describeMismatch(row *fileRow, c *chainTx) string {
+	var diffs []string
+	if row.payment.to != c.payment.to {
+		diffs = append(diffs, fmt.Sprintf("recipient %v instead of %v", recipient(c.tx), row.tx.To))
+	}
+	if row.payment.value != c.payment.value {
+		var value domain.Amount
+		if c.tx.Value != nil {
+			value = domain.NewAmount(c.tx.Value.ToInt())
+		}
+		diffs = append(diffs, fmt.Sprintf("value %v instead of %v", value, row.tx.Amount))
+	}
+	return fmt.Sprintf("transaction with nonce %v has %v", uint64(c.tx.Nonce), strings.Join(diffs, " and "))
+}
+
+// recipient returns recipient of transaction, empty for contract creation
+func recipient(tx *rpcClient.Transaction) string {
+	if tx.To == nil {
+		return ""
+	}
+	return *tx.To
+}
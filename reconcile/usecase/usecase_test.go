@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/core-coin/go-core/v2/common/hexutil"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger/zap"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const (
+	alice = "cb000000000000000000000000000000000000000001"
+	bob   = "cb000000000000000000000000000000000000000002"
+	carol = "cb000000000000000000000000000000000000000003"
+	dave  = "cb000000000000000000000000000000000000000004"
+)
+
+// fakeChain is a node with mined blocks and pending transactions, transactions of failed hashes fail
+type fakeChain struct {
+	rpcClient.Client
+	blocks  []*rpcClient.Block
+	pending []*rpcClient.Transaction
+	failed  map[string]bool
+}
+
+func newTx(hash, from, to string, value int64, nonce uint64) *rpcClient.Transaction {
+	return &rpcClient.Transaction{
+		Hash:  hash,
+		From:  from,
+		To:    &to,
+		Value: (*hexutil.Big)(big.NewInt(value)),
+		Nonce: hexutil.Uint64(nonce),
+	}
+}
+
+// mine is adding a block with transactions
+func (f *fakeChain) mine(txs ...*rpcClient.Transaction) *fakeChain {
+	number := (*hexutil.Big)(big.NewInt(int64(len(f.blocks))))
+	for _, tx := range txs {
+		tx.BlockNumber = number
+	}
+	f.blocks = append(f.blocks, &rpcClient.Block{Number: number, Transactions: txs})
+	return f
+}
+
+func (f *fakeChain) GetTransactionByHash(hash string) (*rpcClient.Transaction, error) {
+	for _, block := range f.blocks {
+		for _, tx := range block.Transactions {
+			if tx.Hash == hash {
+				return tx, nil
+			}
+		}
+	}
+	for _, tx := range f.pending {
+		if tx.Hash == hash {
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeChain) GetTransactionReceipt(hash string) (*rpcClient.Receipt, error) {
+	receipt := &rpcClient.Receipt{TxHash: hash, Status: 1}
+	if f.failed[hash] {
+		receipt.Status = 0
+	}
+	return receipt, nil
+}
+
+func (f *fakeChain) GetBlockNumber() (uint64, error) {
+	return uint64(len(f.blocks) - 1), nil
+}
+
+func (f *fakeChain) GetBlockByNumber(number uint64) (*rpcClient.Block, error) {
+	if number >= uint64(len(f.blocks)) {
+		return nil, nil
+	}
+	return f.blocks[number], nil
+}
+
+// GetAccountNonce is counting transactions of the account mined up to the block given as status
+func (f *fakeChain) GetAccountNonce(account, status string) (uint64, error) {
+	number, err := hexutil.DecodeUint64(status)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected status %q", status)
+	}
+	count := uint64(0)
+	for _, block := range f.blocks[:number+1] {
+		for _, tx := range block.Transactions {
+			if pkg.NormalizeAddress(tx.From) == pkg.NormalizeAddress(account) && uint64(tx.Nonce) >= count {
+				count = uint64(tx.Nonce) + 1
+			}
+		}
+	}
+	return count, nil
+}
+
+func row(from, to string, value int64, nonce string) *domain.Transaction {
+	return &domain.Transaction{From: from, To: to, Amount: domain.NewAmount(big.NewInt(value)), Nonce: nonce}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain func() *fakeChain
+		rows  domain.TransactionList
+		txIDs []string
+		// discrepancies are written as kind/row/hash
+		discrepancies []string
+	}{
+		{
+			name: "every row is mined",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).mine(newTx("0xa", alice, bob, 1, 0)).mine(newTx("0xb", alice, carol, 2, 1))
+			},
+			rows:  domain.TransactionList{row(alice, bob, 1, "0"), row("", carol, 2, "")},
+			txIDs: []string{"0xb", "0xa"},
+		},
+		{
+			name: "missing row and unknown transaction",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).mine(newTx("0xa", alice, bob, 1, 0))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, ""), row(alice, carol, 2, "")},
+			txIDs:         []string{"0xa", "0xc"},
+			discrepancies: []string{"missing/0/0xc", "missing/2/"},
+		},
+		{
+			name: "failed and pending transactions",
+			chain: func() *fakeChain {
+				chain := (&fakeChain{failed: map[string]bool{"0xa": true}}).mine(newTx("0xa", alice, bob, 1, 0))
+				chain.pending = append(chain.pending, newTx("0xb", alice, carol, 2, 1))
+				return chain
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, ""), row(alice, carol, 2, "")},
+			txIDs:         []string{"0xa", "0xb"},
+			discrepancies: []string{"failed/1/0xa", "pending/2/0xb"},
+		},
+		{
+			name: "transaction listed twice and row paid twice",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).mine(newTx("0xa", alice, bob, 1, 0), newTx("0xb", alice, bob, 1, 1))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, "")},
+			txIDs:         []string{"0xa", "0xa", "0xb"},
+			discrepancies: []string{"duplicated/0/0xa", "duplicated/1/0xb"},
+		},
+		{
+			name: "transaction with sender and nonce of the row pays other value",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).mine(newTx("0xa", alice, bob, 3, 0))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, "0")},
+			txIDs:         []string{"0xa"},
+			discrepancies: []string{"mismatch/1/0xa"},
+		},
+		{
+			name: "listed transaction is not in the file",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).mine(newTx("0xa", alice, bob, 1, 0), newTx("0xb", alice, dave, 5, 1))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, "")},
+			txIDs:         []string{"0xa", "0xb"},
+			discrepancies: []string{"unexpected/0/0xb"},
+		},
+		{
+			name: "unlisted transaction between listed nonces",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).
+					mine(newTx("0xa", alice, bob, 1, 0)).
+					mine(newTx("0xx", alice, dave, 9, 1)).
+					mine(newTx("0xb", alice, carol, 2, 2)).
+					mine(newTx("0xy", alice, dave, 9, 3))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, ""), row(alice, carol, 2, "")},
+			txIDs:         []string{"0xa", "0xb"},
+			discrepancies: []string{"unexpected/0/0xx"},
+		},
+		{
+			name: "replacement mined after every listed transaction",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).
+					mine(newTx("0xa", alice, bob, 1, 0)).
+					mine().
+					mine(newTx("0xx", bob, dave, 9, 0)).
+					mine(newTx("0xr", alice, alice, 0, 1)).
+					mine(newTx("0xy", alice, dave, 9, 2))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, "0"), row(alice, carol, 2, "1")},
+			txIDs:         []string{"0xa", "0xb"},
+			discrepancies: []string{"missing/0/0xb", "missing/2/", "unexpected/0/0xr"},
+		},
+		{
+			name: "nonces of rows are not mined yet",
+			chain: func() *fakeChain {
+				return (&fakeChain{}).mine(newTx("0xa", alice, bob, 1, 0))
+			},
+			rows:          domain.TransactionList{row(alice, bob, 1, "0"), row(alice, carol, 2, "1")},
+			txIDs:         []string{"0xa"},
+			discrepancies: []string{"missing/2/"},
+		},
+	}
+
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			uc := NewReconcileUsecase(test.chain(), log)
+			discrepancies, err := uc.Reconcile(test.rows, test.txIDs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range discrepancies {
+				got = append(got, fmt.Sprintf("%v/%v/%v", d.Kind, d.Row, d.Hash))
+			}
+			sort.Strings(got)
+			expected := append([]string{}, test.discrepancies...)
+			sort.Strings(expected)
+			if strings.Join(got, " ") != strings.Join(expected, " ") {
+				t.Errorf("expected discrepancies %v, got %v", expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/go-core/v2/crypto"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+type replacementUsecase struct {
+	logger logger.Logger
+	rpc    rpcClient.Client
+}
+
+// NewReplacementUsecase create new replacement usecase
+func NewReplacementUsecase(rpc rpcClient.Client, log logger.Logger) domain.ReplacementUseCase {
+	return &replacementUsecase{
+		rpc:    rpc,
+		logger: log,
+	}
+}
+
+// BumpTxs is replacing pending transactions of the key owner, mined and unknown transactions are skipped
+func (r *replacementUsecase) BumpTxs(txIDs []string, key *crypto.PrivateKey, factor float64) ([]*domain.Replacement, error) {
+	var replacements []*domain.Replacement
+	if key == nil {
+		return replacements, errors.New("private key is required to bump transactions")
+	}
+	err := checkFactor(factor)
+	if err != nil {
+		return replacements, err
+	}
+	sender := key.Address().Hex()
+
+	for _, txID := range txIDs {
+		tx, err := r.rpc.GetTransactionByHash(txID)
+		if err != nil {
+			return replacements, err
+		}
+		if tx == nil {
+			r.logger.Warnf("Transaction %v is unknown to the node, skipping", txID)
+			continue
+		}
+		if tx.BlockNumber != nil {
+			r.logger.Debugf("Transaction %v is already mined in block %v", txID, tx.BlockNumber.ToInt())
+			continue
+		}
+		if tx.From != sender {
+			r.logger.Warnf("Transaction %v is sent from %v, not from %v, skipping", txID, tx.From, sender)
+			continue
+		}
+		if tx.To == nil {
+			r.logger.Warnf("Transaction %v is a contract creation, skipping", txID)
+			continue
+		}
+		to, err := common.HexToAddress(*tx.To)
+		if err != nil {
+			return replacements, err
+		}
+
+		price := BumpPrice(tx.EnergyPrice.ToInt(), factor)
+		newTx := types.NewTransaction(uint64(tx.Nonce), to, tx.Value.ToInt(), uint64(tx.Energy), price, tx.Input)
+		signedTx, err := pkg.SignTx(newTx, key)
+		if err != nil {
+			return replacements, err
+		}
+		hash, err := r.rpc.SendRawTransaction(signedTx)
+		if err != nil {
+			return replacements, fmt.Errorf("cannot replace transaction %v: %v", txID, err)
+		}
+		r.logger.Infof("Transaction %v with nonce %v replaced by %v with energy price %v", txID, uint64(tx.Nonce), hash, price)
+		replacements = append(replacements, &domain.Replacement{
+			OldHash: txID,
+			NewHash: hash,
+			From:    sender,
+			Nonce:   uint64(tx.Nonce),
+		})
+	}
+	return replacements, nil
+}
+
+// GetCancellationTxs is finding open nonces as the range from the latest to the pending nonce of the sender,
+// together with pending and queued transactions of the sender in the node's pool if the node exposes it.
+// Every cancellation is priced at its transaction's energy price raised by factor, at least at the network price;
+// nonces whose transaction is not in the pool are priced at the network price raised by factor.
+func (r *replacementUsecase) GetCancellationTxs(from string, fromNonce, toNonce uint64, factor float64) (domain.TransactionList, error) {
+	var txs domain.TransactionList
+	err := checkFactor(factor)
+	if err != nil {
+		return txs, err
+	}
+	latest, err := r.rpc.GetAccountNonce(from, "latest")
+	if err != nil {
+		return txs, err
+	}
+	pending, err := r.rpc.GetAccountNonce(from, "pending")
+	if err != nil {
+		return txs, err
+	}
+	open := map[uint64]*rpcClient.Transaction{}
+	for nonce := latest; nonce < pending; nonce++ {
+		if nonce >= fromNonce && nonce <= toNonce {
+			open[nonce] = nil
+		}
+	}
+	pool, err := r.rpc.GetTxPoolContent()
+	if err != nil {
+		r.logger.Warnf("Cannot read transaction pool of the node, cancelling only nonces from latest nonce %v below pending nonce %v: %v", latest, pending, err)
+	} else {
+		err = addPoolTxs(open, pool, from, fromNonce, toNonce)
+		if err != nil {
+			return txs, err
+		}
+	}
+	r.logger.Debugf("Sender %v has %v open nonces in nonce range", from, len(open))
+	if len(open) == 0 {
+		return txs, nil
+	}
+
+	networkPrice, err := r.rpc.EstimateEnergyPrice()
+	if err != nil {
+		return txs, err
+	}
+	nonces := make([]uint64, 0, len(open))
+	for nonce := range open {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+	for _, nonce := range nonces {
+		price := big.NewInt(networkPrice)
+		if tx := open[nonce]; tx != nil && tx.EnergyPrice != nil {
+			bumped := BumpPrice(tx.EnergyPrice.ToInt(), factor)
+			if bumped.Cmp(price) > 0 {
+				price = bumped
+			}
+		} else {
+			price = BumpPrice(price, factor)
+		}
+		txs = append(txs, &domain.Transaction{
+			From:        from,
+			To:          from,
+			EnergyLimit: "21000",
+			EnergyPrice: price.String(),
+			Nonce:       strconv.FormatUint(nonce, 10),
+		})
+	}
+	return txs, nil
+}
+
+// addPoolTxs is adding pending and queued transactions of the sender in nonce range to open nonces
+func addPoolTxs(open map[uint64]*rpcClient.Transaction, pool *rpcClient.TxPoolContent, from string, fromNonce, toNonce uint64) error {
+	sender := pkg.NormalizeAddress(from)
+	for _, group := range []map[string]map[string]*rpcClient.Transaction{pool.Pending, pool.Queued} {
+		for address, byNonce := range group {
+			if pkg.NormalizeAddress(address) != sender {
+				continue
+			}
+			for key, tx := range byNonce {
+				nonce, err := strconv.ParseUint(key, 10, 64)
+				if err != nil {
+					return err
+				}
+				if nonce >= fromNonce && nonce <= toNonce {
+					open[nonce] = tx
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkFactor is refusing bump factors which would not raise energy price
+func checkFactor(factor float64) error {
+	if factor <= 1 {
+		return errors.New("bump factor must be greater than 1")
+	}
+	return nil
+}
+
+// BumpPrice is multiplying price by factor rounding up, the result is always higher than price
+func BumpPrice(price *big.Int, factor float64) *big.Int {
+	bumped := new(big.Float).Mul(new(big.Float).SetInt(price), big.NewFloat(factor))
+	result, accuracy := bumped.Int(nil)
+	if accuracy == big.Below {
+		result.Add(result, big.NewInt(1))
+	}
+	if result.Cmp(price) <= 0 {
+		result = new(big.Int).Add(price, big.NewInt(1))
+	}
+	return result
+}
@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// clashPrefix is put before extra columns named like report columns
+const clashPrefix = "input_"
+
+type reportUsecase struct {
+	pollInterval time.Duration
+	logger       logger.Logger
+	rpc          rpcClient.Client
+}
+
+// NewReportUsecase create new report usecase polling receipts every pollInterval
+func NewReportUsecase(pollInterval time.Duration, rpc rpcClient.Client, log logger.Logger) domain.ReportUseCase {
+	return &reportUsecase{
+		pollInterval: pollInterval,
+		rpc:          rpc,
+		logger:       log,
+	}
+}
+
+// GetResults is taking hashes from signed transactions, so rows have them before streaming,
+// rows without sender get the one who signed them
+func (r *reportUsecase) GetResults(txs domain.TransactionList, signedTxs []string) ([]*domain.Result, error) {
+	if len(txs) != len(signedTxs) {
+		return nil, fmt.Errorf("%v transactions do not match %v signed transactions", len(txs), len(signedTxs))
+	}
+	results := make([]*domain.Result, len(txs))
+	for i, tx := range txs {
+		hash, err := pkg.SignedTxHash(signedTxs[i])
+		if err != nil {
+			return nil, fmt.Errorf("row %v: %v", i+1, err)
+		}
+		if tx.From == "" {
+			signed := *tx
+			signed.From, err = pkg.SignedTxSender(signedTxs[i])
+			if err != nil {
+				return nil, fmt.Errorf("row %v: %v", i+1, err)
+			}
+			tx = &signed
+		}
+		results[i] = &domain.Result{
+			Transaction: tx,
+			Hash:        hash,
+			Status:      domain.ResultSigned,
+		}
+	}
+	return results, nil
+}
+
+// SetStreamOutcome is keeping hash returned by the node, it differs from the signed one only if the node says so
+func (r *reportUsecase) SetStreamOutcome(results []*domain.Result, hashes []string, errs []error) {
+	for i, result := range results {
+		switch {
+		case i < len(errs) && errs[i] != nil:
+			result.Status = domain.ResultFailed
+			result.Error = errs[i].Error()
+		case i < len(hashes) && hashes[i] != "":
+			result.Status = domain.ResultStreamed
+			result.Hash = hashes[i]
+		default:
+			result.Status = domain.ResultNotStreamed
+		}
+	}
+}
+
+// TrackResults is computing actual fee from energy used and energy price of the row
+func (r *reportUsecase) TrackResults(results []*domain.Result, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		waiting := 0
+		for _, result := range results {
+			if result.Status != domain.ResultStreamed && result.Status != domain.ResultPending {
+				continue
+			}
+			receipt, err := r.rpc.GetTransactionReceipt(result.Hash)
+			if err != nil {
+				return err
+			}
+			if receipt == nil {
+				result.Status = domain.ResultPending
+				waiting++
+				continue
+			}
+			setReceipt(result, receipt)
+		}
+		if waiting == 0 {
+			r.logger.Info("All streamed transactions were mined")
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			r.logger.Infof("%v transactions were not mined in %v", waiting, timeout)
+			return nil
+		}
+		r.logger.Infof("Waiting for %v transactions to be mined", waiting)
+		sleep := r.pollInterval
+		if left := time.Until(deadline); left < sleep {
+			sleep = left
+		}
+		time.Sleep(sleep)
+	}
+}
+
+// setReceipt is filling mined row from its receipt
+func setReceipt(result *domain.Result, receipt *rpcClient.Receipt) {
+	result.Status = domain.ResultMined
+	if receipt.Status == 0 {
+		result.Status = domain.ResultReverted
+	}
+	if receipt.BlockNumber != nil {
+		result.BlockNumber = receipt.BlockNumber.ToInt().String()
+	}
+	energyUsed := new(big.Int).SetUint64(uint64(receipt.EnergyUsed))
+	result.EnergyUsed = energyUsed.String()
+	if price, ok := new(big.Int).SetString(result.Transaction.EnergyPrice, 10); ok {
+		result.Fee = new(big.Int).Mul(energyUsed, price).String()
+	}
+}
+
+// WriteResultsToFile is writing CSV for .csv, JSON lines for .jsonl and .ndjson and JSON array otherwise
+func (r *reportUsecase) WriteResultsToFile(results []*domain.Result, fileName string) error {
+	var (
+		data []byte
+		err  error
+	)
+	ext := pkg.OutputExt(fileName)
+	switch {
+	case ext == ".csv":
+		data, err = marshalResultsCSV(results)
+	case pkg.IsJSONLines(ext):
+		data, err = marshalResultsJSON(results, true)
+	default:
+		data, err = marshalResultsJSON(results, false)
+	}
+	if err != nil {
+		return err
+	}
+	return pkg.WriteOutput(fileName, data)
+}
+
+// GetResultsFromFile is reading CSV for .csv, JSON lines for .jsonl and .ndjson and JSON array otherwise
+func (r *reportUsecase) GetResultsFromFile(fileName string) ([]*domain.Result, error) {
+	data, err := pkg.ReadInput(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]string
+	ext := pkg.InputExt(fileName)
+	switch {
+	case ext == ".csv":
+		rows, err = unmarshalRowsCSV(data)
+	case pkg.IsJSONLines(ext):
+		rows, err = unmarshalRowsJSONLines(data)
+	default:
+		rows, err = unmarshalRowsJSON(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*domain.Result, len(rows))
+	for i, row := range rows {
+		results[i], err = rowToResult(row)
+		if err != nil {
+			return nil, fmt.Errorf("row %v: %v", i+1, err)
+		}
+	}
+	return results, nil
+}
+
+// rowToResult is building result from report columns, other columns are extra columns of the row
+func rowToResult(row map[string]string) (*domain.Result, error) {
+	amount, err := domain.ParseAmount(row["amount"])
+	if err != nil {
+		return nil, err
+	}
+	tx := &domain.Transaction{
+		From:        row["from"],
+		To:          row["to"],
+		Amount:      amount,
+		EnergyLimit: row["energy_limit"],
+		EnergyPrice: row["energy_price"],
+		Nonce:       row["nonce"],
+	}
+	for name, value := range row {
+		if isReportColumn(name) {
+			continue
+		}
+		if tx.Extra == nil {
+			tx.Extra = map[string]string{}
+		}
+		tx.Extra[name] = value
+	}
+	return &domain.Result{
+		Transaction: tx,
+		Hash:        row["hash"],
+		Status:      row["status"],
+		Error:       row["error"],
+		BlockNumber: row["block_number"],
+		EnergyUsed:  row["energy_used"],
+		Fee:         row["fee"],
+	}, nil
+}
+
+// unmarshalRowsJSON is reading JSON array of objects, values which are not strings are kept as JSON
+func unmarshalRowsJSON(data []byte) ([]map[string]string, error) {
+	var objects []json.RawMessage
+	err := json.Unmarshal(data, &objects)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]map[string]string, len(objects))
+	for i, object := range objects {
+		rows[i], err = unmarshalRow(object)
+		if err != nil {
+			return nil, fmt.Errorf("row %v: %v", i+1, err)
+		}
+	}
+	return rows, nil
+}
+
+// unmarshalRowsJSONLines is reading a JSON object per non-blank line
+func unmarshalRowsJSONLines(data []byte) ([]map[string]string, error) {
+	var rows []map[string]string
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		row, err := unmarshalRow(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+1, err)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func unmarshalRow(data []byte) (map[string]string, error) {
+	object := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return nil, err
+	}
+	row := map[string]string{}
+	for key, raw := range object {
+		var value string
+		if json.Unmarshal(raw, &value) != nil {
+			value = string(raw)
+		}
+		row[key] = value
+	}
+	return row, nil
+}
+
+// unmarshalRowsCSV is reading records keyed by titles of the first line
+func unmarshalRowsCSV(data []byte) ([]map[string]string, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	titles := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, title := range titles {
+			if i < len(record) {
+				row[title] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// resultFields is a row of results report without extra columns
+type resultFields struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Amount      string `json:"amount"`
+	EnergyLimit string `json:"energy_limit"`
+	EnergyPrice string `json:"energy_price"`
+	Nonce       string `json:"nonce"`
+	Hash        string `json:"hash"`
+	Status      string `json:"status"`
+	Error       string `json:"error"`
+	BlockNumber string `json:"block_number"`
+	EnergyUsed  string `json:"energy_used"`
+	Fee         string `json:"fee"`
+}
+
+// record returns values of the row in the order of report columns
+func (f resultFields) record() []string {
+	return []string{f.From, f.To, f.Amount, f.EnergyLimit, f.EnergyPrice, f.Nonce,
+		f.Hash, f.Status, f.Error, f.BlockNumber, f.EnergyUsed, f.Fee}
+}
+
+func newResultFields(result *domain.Result) resultFields {
+	tx := result.Transaction
+	return resultFields{
+		From:        tx.From,
+		To:          tx.To,
+		Amount:      tx.Amount.String(),
+		EnergyLimit: tx.EnergyLimit,
+		EnergyPrice: tx.EnergyPrice,
+		Nonce:       tx.Nonce,
+		Hash:        result.Hash,
+		Status:      result.Status,
+		Error:       result.Error,
+		BlockNumber: result.BlockNumber,
+		EnergyUsed:  result.EnergyUsed,
+		Fee:         result.Fee,
+	}
+}
+
+// reportExtra returns extra columns of the row, renamed if they clash with report columns
+func reportExtra(result *domain.Result) map[string]string {
+	if len(result.Transaction.Extra) == 0 {
+		return nil
+	}
+	extra := map[string]string{}
+	for name, value := range result.Transaction.Extra {
+		for isReportColumn(name) {
+			name = clashPrefix + name
+		}
+		extra[name] = value
+	}
+	return extra
+}
+
+// isReportColumn is checking whether name is a transaction field or a result column
+func isReportColumn(name string) bool {
+	for _, column := range append(append([]string{}, domain.CSVFields...), domain.ResultColumns...) {
+		if name == column {
+			return true
+		}
+	}
+	return false
+}
+
+// marshalResultsJSON is writing a JSON array, or JSON objects one per line
+func marshalResultsJSON(results []*domain.Result, lines bool) ([]byte, error) {
+	rows := make([]json.RawMessage, len(results))
+	for i, result := range results {
+		data, err := json.Marshal(newResultFields(result))
+		if err != nil {
+			return nil, err
+		}
+		rows[i], err = domain.AppendExtra(data, reportExtra(result))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !lines {
+		return json.Marshal(rows)
+	}
+	var buf bytes.Buffer
+	for _, row := range rows {
+		buf.Write(row)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalResultsCSV is writing titles and a record per row, extra columns follow in sorted order
+func marshalResultsCSV(results []*domain.Result) ([]byte, error) {
+	extras := make([]map[string]string, len(results))
+	var extraColumns []string
+	seen := map[string]bool{}
+	for i, result := range results {
+		extras[i] = reportExtra(result)
+		for name := range extras[i] {
+			if !seen[name] {
+				seen[name] = true
+				extraColumns = append(extraColumns, name)
+			}
+		}
+	}
+	sort.Strings(extraColumns)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	titles := append(append(append([]string{}, domain.CSVFields...), domain.ResultColumns...), extraColumns...)
+	err := w.Write(titles)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		record := newResultFields(result).record()
+		for _, name := range extraColumns {
+			record = append(record, extras[i][name])
+		}
+		err = w.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+)
+
+// maxBlockPollFailures is the number of failed block polls in a row logged as warnings, later ones are errors
+const maxBlockPollFailures = 5
+
+type scheduleUsecase struct {
+	pollInterval time.Duration
+	logger       logger.Logger
+	rpc          rpcClient.Client
+}
+
+// NewScheduleUsecase create new schedule usecase polling the node every pollInterval
+func NewScheduleUsecase(pollInterval time.Duration, rpc rpcClient.Client, log logger.Logger) domain.ScheduleUseCase {
+	return &scheduleUsecase{
+		pollInterval: pollInterval,
+		rpc:          rpc,
+		logger:       log,
+	}
+}
+
+// WaitForEnergyPrice is logging every decision to wait or to start streaming, failed polls are retried
+func (s *scheduleUsecase) WaitForEnergyPrice(maxPrice *big.Int, deadline time.Time) error {
+	for {
+		price, err := s.rpc.EstimateEnergyPrice()
+		if err != nil {
+			if !deadline.IsZero() && !time.Now().Before(deadline) {
+				s.logger.Infof("Cannot get energy price: %v, but deadline %v passed, streaming", err, deadline.Format(time.RFC3339))
+				return nil
+			}
+			s.logger.Warnf("Cannot get energy price: %v, retrying in %v", err, s.pollInterval)
+			s.sleep(deadline)
+			continue
+		}
+		if big.NewInt(price).Cmp(maxPrice) <= 0 {
+			s.logger.Infof("Energy price %v is not above %v, streaming", price, maxPrice)
+			return nil
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			s.logger.Infof("Energy price %v is above %v, but deadline %v passed, streaming", price, maxPrice, deadline.Format(time.RFC3339))
+			return nil
+		}
+		s.logger.Infof("Energy price %v is above %v, waiting", price, maxPrice)
+		s.sleep(deadline)
+	}
+}
+
+// WaitForTime is sleeping in poll intervals to report progress in debug logs
+func (s *scheduleUsecase) WaitForTime(notBefore time.Time) error {
+	if time.Now().Before(notBefore) {
+		s.logger.Infof("Waiting until %v, %v left", notBefore.Format(time.RFC3339), time.Until(notBefore).Round(time.Second))
+	}
+	for time.Now().Before(notBefore) {
+		s.sleep(notBefore)
+		s.logger.Debugf("Waiting until %v, %v left", notBefore.Format(time.RFC3339), time.Until(notBefore).Round(time.Second))
+	}
+	s.logger.Infof("Time %v came, streaming", notBefore.Format(time.RFC3339))
+	return nil
+}
+
+// WaitForBlock is polling block number of the node, failed polls are retried until deadline
+func (s *scheduleUsecase) WaitForBlock(notBefore uint64, deadline time.Time) error {
+	failures := 0
+	for {
+		number, err := s.rpc.GetBlockNumber()
+		if err != nil {
+			failures++
+			if failures > maxBlockPollFailures {
+				s.logger.Errorf("Cannot get block number for %v polls in a row: %v, retrying in %v", failures, err, s.pollInterval)
+			} else {
+				s.logger.Warnf("Cannot get block number: %v, retrying in %v", err, s.pollInterval)
+			}
+		} else {
+			failures = 0
+			if number >= notBefore {
+				s.logger.Infof("Block %v is reached, streaming", number)
+				return nil
+			}
+			s.logger.Infof("Waiting for block %v, current block is %v", notBefore, number)
+		}
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return fmt.Errorf("block %v is not reached by %v", notBefore, deadline.Format(time.RFC3339))
+		}
+		s.sleep(deadline)
+	}
+}
+
+// sleep is waiting for the next poll but not longer than till deadline
+func (s *scheduleUsecase) sleep(deadline time.Time) {
+	wait := s.pollInterval
+	if !deadline.IsZero() && time.Until(deadline) < wait {
+		wait = time.Until(deadline)
+	}
+	time.Sleep(wait)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const defaultEnergyLimit = 21000
+
+type senderPoolUsecase struct {
+	logger logger.Logger
+	rpc    rpcClient.Client
+	prices domain.EnergyPriceUseCase
+}
+
+// NewSenderPoolUsecase create new sender pool usecase
+func NewSenderPoolUsecase(rpc rpcClient.Client, prices domain.EnergyPriceUseCase, log logger.Logger) domain.SenderPoolUseCase {
+	return &senderPoolUsecase{
+		rpc:    rpc,
+		prices: prices,
+		logger: log,
+	}
+}
+
+type poolSender struct {
+	address  string
+	balance  *big.Int
+	target   float64
+	assigned int
+}
+
+// AssignSenders is giving the largest payments first to the sender which is the most behind its share of rows
+// and still can pay for the transaction
+func (p *senderPoolUsecase) AssignSenders(txs domain.TransactionList, senders []string) error {
+	if len(senders) == 0 {
+		return errors.New("sender pool is empty")
+	}
+
+	pool := make([]*poolSender, 0, len(senders))
+	total := new(big.Int)
+	for _, address := range senders {
+		balance, err := p.rpc.GetBalance(address, "pending")
+		if err != nil {
+			return err
+		}
+		p.logger.Debugf("Sender %v has balance %v", address, pkg.FormatOre(balance))
+		pool = append(pool, &poolSender{address: address, balance: balance})
+		total.Add(total, balance)
+	}
+	if total.Sign() == 0 {
+		return errors.New("all senders in the pool have zero balance")
+	}
+	for _, sender := range pool {
+		share, _ := new(big.Rat).SetFrac(sender.balance, total).Float64()
+		sender.target = share * float64(len(txs))
+	}
+
+	rows := make([]int, len(txs))
+	for i := range rows {
+		rows[i] = i
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return txs[rows[i]].Amount.Cmp(txs[rows[j]].Amount) > 0
+	})
+
+	for _, row := range rows {
+		tx := txs[row]
+		if tx.From != "" {
+			return fmt.Errorf("row %v: sender is already set", row+1)
+		}
+		cost, err := p.maxCost(tx)
+		if err != nil {
+			return fmt.Errorf("row %v: %v", row+1, err)
+		}
+
+		var chosen *poolSender
+		for _, sender := range pool {
+			if sender.balance.Cmp(cost) < 0 {
+				continue
+			}
+			if chosen == nil || sender.target-float64(sender.assigned) > chosen.target-float64(chosen.assigned) {
+				chosen = sender
+			}
+		}
+		if chosen == nil {
+			return fmt.Errorf("row %v: no sender in the pool can pay %v with fees", row+1, tx.Amount)
+		}
+		tx.From = chosen.address
+		chosen.balance.Sub(chosen.balance, cost)
+		chosen.assigned++
+	}
+
+	for _, sender := range pool {
+		p.logger.Infof("Sender %v got %v transactions", sender.address, sender.assigned)
+	}
+	return nil
+}
+
+// maxCost is amount plus the highest fee of the transaction
+func (p *senderPoolUsecase) maxCost(tx *domain.Transaction) (*big.Int, error) {
+	limit := big.NewInt(defaultEnergyLimit)
+	if tx.EnergyLimit != "" {
+		var ok bool
+		limit, ok = new(big.Int).SetString(tx.EnergyLimit, 10)
+		if !ok {
+			return nil, errors.New("energy limit has bad number")
+		}
+	}
+	var price *big.Int
+	if tx.EnergyPrice != "" {
+		var ok bool
+		price, ok = new(big.Int).SetString(tx.EnergyPrice, 10)
+		if !ok {
+			return nil, errors.New("energy price has bad number")
+		}
+	} else {
+		var err error
+		price, err = p.prices.GetEnergyPrice()
+		if err != nil {
+			return nil, err
+		}
+	}
+	cost := new(big.Int).Mul(limit, price)
+	return cost.Add(cost, tx.Amount.Ore()), nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const sweepEnergyLimit = 21000
+
+type sweepUsecase struct {
+	logger logger.Logger
+	rpc    rpcClient.Client
+	prices domain.EnergyPriceUseCase
+}
+
+// NewSweepUsecase create new sweep usecase
+func NewSweepUsecase(rpc rpcClient.Client, prices domain.EnergyPriceUseCase, log logger.Logger) domain.SweepUseCase {
+	return &sweepUsecase{
+		rpc:    rpc,
+		prices: prices,
+		logger: log,
+	}
+}
+
+// GetSweepTxs is taking pending balances, so transactions in flight are already paid
+func (s *sweepUsecase) GetSweepTxs(senders []string, to string) (domain.TransactionList, error) {
+	var txs domain.TransactionList
+	price, err := s.prices.GetEnergyPrice()
+	if err != nil {
+		return txs, err
+	}
+	fee := new(big.Int).Mul(big.NewInt(sweepEnergyLimit), price)
+
+	for _, sender := range senders {
+		if pkg.NormalizeAddress(sender) == pkg.NormalizeAddress(to) {
+			s.logger.Warnf("Sender %v is the destination, skipping", sender)
+			continue
+		}
+		balance, err := s.rpc.GetBalance(sender, "pending")
+		if err != nil {
+			return txs, err
+		}
+		if balance.Cmp(fee) <= 0 {
+			s.logger.Warnf("Balance %v of sender %v does not cover fee %v, skipping", pkg.FormatOre(balance), sender, pkg.FormatOre(fee))
+			continue
+		}
+		amount := new(big.Int).Sub(balance, fee)
+		s.logger.Infof("Sweeping %v of %v from sender %v", pkg.FormatOre(amount), pkg.FormatOre(balance), sender)
+		txs = append(txs, &domain.Transaction{
+			From:        sender,
+			To:          to,
+			Amount:      domain.NewAmount(amount),
+			EnergyLimit: strconv.Itoa(sweepEnergyLimit),
+			EnergyPrice: price.String(),
+		})
+	}
+	return txs, nil
+}
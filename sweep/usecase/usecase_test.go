@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/core-coin/pigeon/infrastructure/rpcClient"
+	"github.com/core-coin/pigeon/logger/zap"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+const (
+	alice = "cb000000000000000000000000000000000000000001"
+	bob   = "cb000000000000000000000000000000000000000002"
+	carol = "cb000000000000000000000000000000000000000003"
+)
+
+// fakeRPC is answering pending balances, other calls are not used by sweep
+type fakeRPC struct {
+	rpcClient.Client
+	balances map[string]int64
+}
+
+func (f *fakeRPC) GetBalance(account, status string) (*big.Int, error) {
+	return big.NewInt(f.balances[pkg.NormalizeAddress(account)]), nil
+}
+
+type fixedPrice int64
+
+func (p fixedPrice) GetEnergyPrice() (*big.Int, error) {
+	return big.NewInt(int64(p)), nil
+}
+
+func TestGetSweepTxs(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance int64
+		sender  string
+		amount  int64
+		skipped bool
+	}{
+		{name: "balance minus fee", sender: alice, balance: 1000000, amount: 1000000 - 21000*10},
+		{name: "balance equal to fee", sender: alice, balance: 21000 * 10, skipped: true},
+		{name: "balance below fee", sender: alice, balance: 21000*10 - 1, skipped: true},
+		{name: "empty balance", sender: alice, skipped: true},
+		{name: "sender is destination", sender: carol, balance: 1000000, skipped: true},
+		{name: "sender is destination in other case", sender: "CB000000000000000000000000000000000000000003", balance: 1000000, skipped: true},
+	}
+
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rpc := &fakeRPC{balances: map[string]int64{pkg.NormalizeAddress(test.sender): test.balance}}
+			uc := NewSweepUsecase(rpc, fixedPrice(10), log)
+			txs, err := uc.GetSweepTxs([]string{test.sender}, carol)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.skipped {
+				if len(txs) != 0 {
+					t.Fatalf("expected sender to be skipped, got %v transactions", len(txs))
+				}
+				return
+			}
+			if len(txs) != 1 {
+				t.Fatalf("expected 1 transaction, got %v", len(txs))
+			}
+			tx := txs[0]
+			if tx.Amount.Ore().Int64() != test.amount {
+				t.Errorf("expected amount %v ore, got %v", test.amount, tx.Amount.Ore())
+			}
+			if tx.From != test.sender || tx.To != carol || tx.EnergyLimit != "21000" || tx.EnergyPrice != "10" {
+				t.Errorf("unexpected transaction %+v", tx)
+			}
+		})
+	}
+}
+
+func TestGetSweepTxsOfSeveralSenders(t *testing.T) {
+	log := zap.NewApiLogger(4)
+	log.InitLogger()
+	rpc := &fakeRPC{balances: map[string]int64{alice: 500000, bob: 100}}
+	uc := NewSweepUsecase(rpc, fixedPrice(1), log)
+	txs, err := uc.GetSweepTxs([]string{alice, bob, carol}, carol)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 1 || txs[0].From != alice || txs[0].Amount.Ore().Int64() != 500000-21000 {
+		t.Fatalf("expected only sweep of %v, got %v transactions", alice, len(txs))
+	}
+}
@@ -0,0 +1,321 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// getTxsFromCSV is loading transaction from csv file
+func (t *transactionListUsecase) getTxsFromCSV(fileName string, format *domain.CSVFormat) ([]*domain.Transaction, error) {
+	if format == nil {
+		format = &domain.CSVFormat{}
+	}
+	in, err := pkg.OpenInput(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	read, err := newCSVReader(in, format)
+	if err != nil {
+		return nil, err
+	}
+	return readTable(func() ([]string, int, error) {
+		record, err := read.Read()
+		if err != nil {
+			return nil, 0, err
+		}
+		line, _ := read.FieldPos(0)
+		return record, line, nil
+	}, format)
+}
+
+// readTable is building transactions from records returned by next until io.EOF, first record may be titles
+func readTable(next func() ([]string, int, error), format *domain.CSVFormat) ([]*domain.Transaction, error) {
+	txs := []*domain.Transaction{}
+	var columns, extra map[string]int
+	for {
+		record, line, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if columns == nil {
+			var titles bool
+			columns, extra, titles, err = mapCSVColumns(record, format)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line, err)
+			}
+			if titles {
+				continue
+			}
+		}
+		if isEmptyRecord(record) {
+			continue
+		}
+		tx, err := csvRecordToTx(record, columns, extra, format)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line, err)
+		}
+		txs = append(txs, tx)
+	}
+	return txs, nil
+}
+
+// marshalTxsCSV is writing transactions with titles, extra columns follow the fields in sorted order
+func marshalTxsCSV(txs domain.TransactionList) ([]byte, error) {
+	var extraColumns []string
+	seen := map[string]bool{}
+	for _, tx := range txs {
+		for name := range tx.Extra {
+			if !seen[name] {
+				seen[name] = true
+				extraColumns = append(extraColumns, name)
+			}
+		}
+	}
+	sort.Strings(extraColumns)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	err := w.Write(append(append([]string{}, domain.CSVFields...), extraColumns...))
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		record := []string{tx.From, tx.To, tx.Amount.String(), tx.EnergyLimit, tx.EnergyPrice, tx.Nonce}
+		for _, name := range extraColumns {
+			record = append(record, tx.Extra[name])
+		}
+		err = w.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// newCSVReader is skipping UTF-8 byte order mark and configuring delimiter and comments
+func newCSVReader(in io.Reader, format *domain.CSVFormat) (*csv.Reader, error) {
+	buffered := bufio.NewReader(in)
+	head, _ := buffered.Peek(len(utf8BOM))
+	if bytes.HasPrefix(head, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	} else if bytes.HasPrefix(head, utf16LEBOM) || bytes.HasPrefix(head, utf16BEBOM) {
+		return nil, errors.New("file is encoded in UTF-16, save it as UTF-8")
+	}
+
+	read := csv.NewReader(buffered)
+	if format.Delimiter != 0 {
+		read.Comma = format.Delimiter
+	}
+	read.Comment = format.Comment
+	read.FieldsPerRecord = -1
+	read.TrimLeadingSpace = true
+	return read, nil
+}
+
+// mapCSVColumns is finding 0-based source column of every field, -1 if the field is missing,
+// and columns of other titles which are carried as extra columns.
+// The first record is titles if format says so or if it names the column of the to field.
+func mapCSVColumns(first []string, format *domain.CSVFormat) (map[string]int, map[string]int, bool, error) {
+	for field := range format.Columns {
+		if !isCSVField(field) {
+			return nil, nil, false, fmt.Errorf("unknown field %q in column mapping, fields are %v", field, strings.Join(domain.CSVFields, ", "))
+		}
+	}
+
+	titleIndex := map[string]int{}
+	for i, title := range first {
+		title = strings.ToLower(strings.TrimSpace(title))
+		if _, ok := titleIndex[title]; !ok {
+			titleIndex[title] = i
+		}
+	}
+	source := func(field string) string {
+		if column, ok := format.Columns[field]; ok {
+			return strings.TrimSpace(column)
+		}
+		return field
+	}
+	_, titles := titleIndex[strings.ToLower(source("to"))]
+	titles = titles || format.Titles
+
+	columns := map[string]int{}
+	for position, field := range domain.CSVFields {
+		column := source(field)
+		if number, err := strconv.Atoi(column); err == nil {
+			if number < 1 {
+				return nil, nil, false, fmt.Errorf("bad column number %v of field %v", number, field)
+			}
+			columns[field] = number - 1
+			continue
+		}
+		_, mapped := format.Columns[field]
+		if !titles {
+			if mapped {
+				return nil, nil, false, fmt.Errorf("column %q of field %v is not found, the file has no titles", column, field)
+			}
+			// with a mapping by numbers every field read has to be mapped, default positions would overlap it
+			if len(format.Columns) > 0 {
+				if field == "to" {
+					return nil, nil, false, fmt.Errorf("column of field to is not mapped, the file has no titles")
+				}
+				columns[field] = -1
+				continue
+			}
+			columns[field] = position
+			continue
+		}
+		index, ok := titleIndex[strings.ToLower(column)]
+		if !ok {
+			if mapped || field == "to" {
+				return nil, nil, false, fmt.Errorf("column %q of field %v is not found in titles", column, field)
+			}
+			index = -1
+		}
+		columns[field] = index
+	}
+	fields := map[int]string{}
+	for _, field := range domain.CSVFields {
+		index := columns[field]
+		if index < 0 {
+			continue
+		}
+		if other, ok := fields[index]; ok {
+			return nil, nil, false, fmt.Errorf("fields %v and %v are mapped to the same column %v", other, field, index+1)
+		}
+		fields[index] = field
+	}
+	if !titles {
+		return columns, nil, false, nil
+	}
+
+	used := map[int]bool{}
+	for _, index := range columns {
+		used[index] = true
+	}
+	extra := map[string]int{}
+	for i, title := range first {
+		title = strings.TrimSpace(title)
+		if used[i] || title == "" {
+			continue
+		}
+		if domain.IsReservedColumn(strings.ToLower(title)) {
+			return nil, nil, false, fmt.Errorf("column %q is reserved", title)
+		}
+		if _, ok := extra[title]; !ok {
+			extra[title] = i
+		}
+	}
+	return columns, extra, true, nil
+}
+
+// csvRecordToTx is building transaction from mapped columns of a record
+func csvRecordToTx(record []string, columns, extra map[string]int, format *domain.CSVFormat) (*domain.Transaction, error) {
+	value := func(field string) string {
+		index := columns[field]
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	amount, err := normalizeNumber(value("amount"), format)
+	if err != nil {
+		return nil, fmt.Errorf("amount: %v", err)
+	}
+	tx := &domain.Transaction{
+		From:  value("from"),
+		To:    value("to"),
+		Nonce: value("nonce"),
+	}
+	tx.Amount, err = domain.ParseAmount(amount)
+	if err != nil {
+		return nil, err
+	}
+	tx.EnergyLimit, err = normalizeNumber(value("energy_limit"), format)
+	if err != nil {
+		return nil, fmt.Errorf("energy limit: %v", err)
+	}
+	tx.EnergyPrice, err = normalizeNumber(value("energy_price"), format)
+	if err != nil {
+		return nil, fmt.Errorf("energy price: %v", err)
+	}
+	if len(extra) > 0 {
+		tx.Extra = map[string]string{}
+		for name, index := range extra {
+			if index < len(record) {
+				tx.Extra[name] = strings.TrimSpace(record[index])
+			} else {
+				tx.Extra[name] = ""
+			}
+		}
+	}
+	return tx, nil
+}
+
+// normalizeNumber is removing thousands separators and replacing decimal separator with a dot.
+// Groups between thousands separators must have 3 digits, so a misconfigured decimal separator is not dropped silently.
+func normalizeNumber(value string, format *domain.CSVFormat) (string, error) {
+	if value == "" {
+		return value, nil
+	}
+	decimal := format.DecimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	integer, fraction, hasFraction := strings.Cut(value, decimal)
+	if format.ThousandsSeparator != "" && strings.Contains(integer, format.ThousandsSeparator) {
+		groups := strings.Split(integer, format.ThousandsSeparator)
+		for i, group := range groups {
+			if (i == 0 && (group == "" || len(strings.TrimLeft(group, "+-")) > 3)) || (i > 0 && len(group) != 3) {
+				return "", fmt.Errorf("bad thousands grouping in %q", value)
+			}
+		}
+		integer = strings.Join(groups, "")
+	}
+	if hasFraction {
+		return integer + "." + fraction, nil
+	}
+	return integer, nil
+}
+
+// isCSVField is checking whether field can be mapped to a column
+func isCSVField(field string) bool {
+	for _, known := range domain.CSVFields {
+		if field == known {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmptyRecord is checking whether all fields of a record are blank
+func isEmptyRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
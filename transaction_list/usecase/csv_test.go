@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/core-coin/pigeon/domain"
+)
+
+func TestNormalizeAmount(t *testing.T) {
+	tests := []struct {
+		value      string
+		thousands  string
+		decimal    string
+		ore        string
+		errMessage string
+	}{
+		{value: "1234.5", ore: "1234500000000000000000"},
+		{value: "1,234.50", thousands: ",", ore: "1234500000000000000000"},
+		{value: "1,234,567", thousands: ",", ore: "1234567000000000000000000"},
+		{value: "1.234,50", thousands: ".", decimal: ",", ore: "1234500000000000000000"},
+		{value: "1 234,5", thousands: " ", decimal: ",", ore: "1234500000000000000000"},
+		{value: "0,000000000000000001", decimal: ",", ore: "1"},
+		{value: "1,5e3", decimal: ",", ore: "1500000000000000000000"},
+		{value: "0,0000000000000000001", decimal: ",", errMessage: "more than 18 decimals"},
+		{value: "1,23.5", thousands: ",", errMessage: "bad thousands grouping"},
+		{value: "1234,567.5", thousands: ",", errMessage: "bad thousands grouping"},
+		{value: ",234", thousands: ",", errMessage: "bad thousands grouping"},
+		{value: "1.234,5", thousands: ",", errMessage: "bad amount"},
+		{value: "1,5", errMessage: "bad amount"},
+		{value: "-1,234.5", thousands: ",", errMessage: "negative"},
+		{value: "-0,5", decimal: ",", errMessage: "negative"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			format := &domain.CSVFormat{ThousandsSeparator: test.thousands, DecimalSeparator: test.decimal}
+			value, err := normalizeNumber(test.value, format)
+			var amount domain.Amount
+			if err == nil {
+				amount, err = domain.ParseAmount(value)
+			}
+			if test.errMessage != "" {
+				if err == nil || !strings.Contains(err.Error(), test.errMessage) {
+					t.Fatalf("expected error with %q, got %v (amount %v)", test.errMessage, err, amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if amount.Ore().String() != test.ore {
+				t.Errorf("expected %v ore, got %v", test.ore, amount.Ore())
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/core-coin/pigeon/domain"
+)
+
+// Entries of signed transactions and hashes files are plain strings, or objects with the value under key
+// and extra columns of the row next to it when the batch has extra columns.
+
+// encodeEntry is writing value as JSON string, or as object with extra columns if the batch has them
+func encodeEntry(key, value string, extras []map[string]string, i int) ([]byte, error) {
+	if extras == nil {
+		return json.Marshal(value)
+	}
+	object, err := json.Marshal(map[string]string{key: value})
+	if err != nil {
+		return nil, err
+	}
+	return domain.AppendExtra(object, extraAt(extras, i))
+}
+
+// decodeEntry is reading JSON string or object with value under key and extra columns
+func decodeEntry(data []byte, key string) (string, map[string]string, error) {
+	var value string
+	if len(data) > 0 && data[0] == '"' {
+		err := json.Unmarshal(data, &value)
+		return value, nil, err
+	}
+	object := map[string]json.RawMessage{}
+	err := json.Unmarshal(data, &object)
+	if err != nil {
+		return "", nil, err
+	}
+	raw, ok := object[key]
+	if !ok {
+		return "", nil, fmt.Errorf("entry has no %v", key)
+	}
+	err = json.Unmarshal(raw, &value)
+	if err != nil {
+		return "", nil, fmt.Errorf("%v: %v", key, err)
+	}
+	extra, err := domain.ParseExtra(data, key)
+	if err != nil {
+		return "", nil, err
+	}
+	return value, extra, nil
+}
+
+// encodeEntries is writing JSON array of entries
+func encodeEntries(key string, values []string, extras []map[string]string) ([]byte, error) {
+	entries := make([]json.RawMessage, len(values))
+	for i, value := range values {
+		entry, err := encodeEntry(key, value, extras, i)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = entry
+	}
+	return json.Marshal(entries)
+}
+
+// decodeEntries is reading JSON array of entries
+func decodeEntries(data []byte, key string) ([]string, []map[string]string, error) {
+	var entries []json.RawMessage
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make([]string, len(entries))
+	extras := make([]map[string]string, len(entries))
+	for i, entry := range entries {
+		values[i], extras[i], err = decodeEntry(entry, key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("entry %v: %v", i+1, err)
+		}
+	}
+	return values, compactExtras(extras), nil
+}
+
+// extraAt returns extra columns of entry i, extras may be nil or shorter
+func extraAt(extras []map[string]string, i int) map[string]string {
+	if i < len(extras) {
+		return extras[i]
+	}
+	return nil
+}
+
+// compactExtras returns nil if no entry has extra columns
+func compactExtras(extras []map[string]string) []map[string]string {
+	for _, extra := range extras {
+		if len(extra) > 0 {
+			return extras
+		}
+	}
+	return nil
+}
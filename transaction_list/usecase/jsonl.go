@@ -0,0 +1,140 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// maxJSONLine is the longest line of JSON lines files
+const maxJSONLine = 1024 * 1024
+
+// readJSONLines is calling handle for every non-blank line of file without loading the whole file, handle reports line of its errors
+func readJSONLines(fileName string, handle func(line int, data []byte) error) error {
+	in, err := pkg.OpenInput(fileName)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxJSONLine)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if line == 1 {
+			data = bytes.TrimPrefix(data, utf8BOM)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		err = handle(line, data)
+		if err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// readEntryLines is reading entries, one per line, bare values are accepted as well
+func readEntryLines(fileName, key string) ([]string, []map[string]string, error) {
+	var values []string
+	var extras []map[string]string
+	err := readJSONLines(fileName, func(line int, data []byte) error {
+		if data[0] != '"' && data[0] != '{' {
+			values = append(values, string(data))
+			extras = append(extras, nil)
+			return nil
+		}
+		value, extra, err := decodeEntry(data, key)
+		if err != nil {
+			return fmt.Errorf("line %v: %v", line, err)
+		}
+		values = append(values, value)
+		extras = append(extras, extra)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return values, compactExtras(extras), nil
+}
+
+// writeEntryLines is writing entries, one per line, flag tells whether to truncate or append
+func writeEntryLines(fileName string, flag int, key string, values []string, extras []map[string]string) error {
+	out, err := pkg.OpenOutput(fileName, flag)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	for i, value := range values {
+		data, err := encodeEntry(key, value, extras, i)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+	}
+	err = w.Flush()
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// readTxLines is calling handle with chunks of transactions read from JSON lines file
+func readTxLines(fileName string, size int, handle func(domain.TransactionList) error) error {
+	chunk := make(domain.TransactionList, 0, size)
+	err := readJSONLines(fileName, func(line int, data []byte) error {
+		tx := &domain.Transaction{}
+		err := json.Unmarshal(data, tx)
+		if err != nil {
+			return fmt.Errorf("line %v: %v", line, err)
+		}
+		chunk = append(chunk, tx)
+		if len(chunk) < size {
+			return nil
+		}
+		err = handle(chunk)
+		chunk = make(domain.TransactionList, 0, size)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(chunk) > 0 {
+		return handle(chunk)
+	}
+	return nil
+}
+
+// writeTxLines is writing transactions as JSON objects, one per line
+func writeTxLines(fileName string, txs domain.TransactionList) error {
+	out, err := pkg.OpenOutput(fileName, os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	encoder := json.NewEncoder(w)
+	for _, tx := range txs {
+		err = encoder.Encode(tx)
+		if err != nil {
+			out.Close()
+			return err
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
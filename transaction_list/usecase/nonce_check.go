@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// nonceSpan is a range of contiguous nonces of a sender
+type nonceSpan struct {
+	first, last uint64
+}
+
+// nonceChecker keeps nonces of every sender as merged spans, so a batch checked in chunks
+// does not keep a row per nonce
+type nonceChecker struct {
+	t         *transactionListUsecase
+	rows      int
+	senders   []string
+	spans     map[string][]nonceSpan
+	confirmed map[string]uint64
+	unknown   map[string]bool
+}
+
+// NewNonceChecker create new checker of nonces of a batch read in chunks
+func (t *transactionListUsecase) NewNonceChecker() domain.NonceChecker {
+	return &nonceChecker{
+		t:         t,
+		spans:     map[string][]nonceSpan{},
+		confirmed: map[string]uint64{},
+		unknown:   map[string]bool{},
+	}
+}
+
+// CheckNonces is looking for duplicate, non-contiguous and already confirmed nonces of every sender
+func (t *transactionListUsecase) CheckNonces(txs domain.TransactionList) error {
+	c := t.NewNonceChecker().(*nonceChecker)
+	problems, err := c.check(txs)
+	if err != nil {
+		return err
+	}
+	return nonceProblems(append(problems, c.gaps()...))
+}
+
+// Check is looking for nonces used twice, also in earlier chunks, and for already confirmed nonces.
+// Rows are numbered over all chunks.
+func (c *nonceChecker) Check(txs domain.TransactionList) error {
+	problems, err := c.check(txs)
+	if err != nil {
+		return err
+	}
+	return nonceProblems(problems)
+}
+
+// Finish is looking for nonces missing between the nonces of every sender over all chunks
+func (c *nonceChecker) Finish() error {
+	return nonceProblems(c.gaps())
+}
+
+// check is returning problems of a chunk and adding its nonces to spans
+func (c *nonceChecker) check(txs domain.TransactionList) ([]string, error) {
+	var problems []string
+	rows := map[string]map[uint64]int{}
+	var senders []string
+	for i, tx := range txs {
+		row := c.rows + i + 1
+		sender := pkg.NormalizeAddress(tx.From)
+		nonce, err := strconv.ParseUint(tx.Nonce, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %v: bad nonce %q: %v", row, tx.Nonce, err)
+		}
+		if _, ok := rows[sender]; !ok {
+			rows[sender] = map[uint64]int{}
+			senders = append(senders, sender)
+		}
+		if earlier, ok := rows[sender][nonce]; ok {
+			problems = append(problems, fmt.Sprintf("row %v: nonce %v of sender %v is already used in row %v", row, nonce, tx.From, earlier))
+			continue
+		}
+		if c.contains(sender, nonce) {
+			problems = append(problems, fmt.Sprintf("row %v: nonce %v of sender %v is already used in an earlier row", row, nonce, tx.From))
+			continue
+		}
+		rows[sender][nonce] = row
+	}
+	c.rows += len(txs)
+
+	for _, sender := range senders {
+		nonces := make([]uint64, 0, len(rows[sender]))
+		for nonce := range rows[sender] {
+			nonces = append(nonces, nonce)
+		}
+		sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+		c.add(sender, nonces)
+
+		confirmed, ok := c.confirmedNonce(sender)
+		if !ok {
+			continue
+		}
+		for _, nonce := range nonces {
+			if nonce >= confirmed {
+				break
+			}
+			problems = append(problems, fmt.Sprintf("row %v: nonce %v of sender %v is already confirmed, next nonce is %v", rows[sender][nonce], nonce, sender, confirmed))
+		}
+	}
+	return problems, nil
+}
+
+// gaps is listing nonces missing between spans of every sender
+func (c *nonceChecker) gaps() []string {
+	var problems []string
+	for _, sender := range c.senders {
+		spans := c.spans[sender]
+		for i := 1; i < len(spans); i++ {
+			problems = append(problems, fmt.Sprintf("sender %v: nonces %v-%v are missing", sender, spans[i-1].last+1, spans[i].first-1))
+		}
+	}
+	return problems
+}
+
+// contains tells whether nonce of sender was seen in an earlier chunk
+func (c *nonceChecker) contains(sender string, nonce uint64) bool {
+	for _, span := range c.spans[sender] {
+		if nonce >= span.first && nonce <= span.last {
+			return true
+		}
+	}
+	return false
+}
+
+// add is merging sorted unique nonces into spans of sender
+func (c *nonceChecker) add(sender string, nonces []uint64) {
+	spans, ok := c.spans[sender]
+	if !ok {
+		c.senders = append(c.senders, sender)
+	}
+	for _, nonce := range nonces {
+		spans = append(spans, nonceSpan{nonce, nonce})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].first < spans[j].first })
+	merged := spans[:0]
+	for _, span := range spans {
+		if n := len(merged); n > 0 && span.first <= merged[n-1].last+1 {
+			if span.last > merged[n-1].last {
+				merged[n-1].last = span.last
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	c.spans[sender] = merged
+}
+
+// confirmedNonce is getting latest nonce of sender once per batch, false if the node cannot tell it
+func (c *nonceChecker) confirmedNonce(sender string) (uint64, bool) {
+	if nonce, ok := c.confirmed[sender]; ok {
+		return nonce, true
+	}
+	if c.unknown[sender] {
+		return 0, false
+	}
+	nonce, err := c.t.rpc.GetAccountNonce(sender, "latest")
+	if err != nil {
+		c.t.logger.Warnf("Cannot check confirmed nonce of sender %v: %v", sender, err)
+		c.unknown[sender] = true
+		return 0, false
+	}
+	c.confirmed[sender] = nonce
+	return nonce, true
+}
+
+// nonceProblems is joining problems into one error, nil if there are none
+func nonceProblems(problems []string) error {
+	if len(problems) > 0 {
+		return fmt.Errorf("bad nonces in transactions:\n%v", strings.Join(problems, "\n"))
+	}
+	return nil
+}
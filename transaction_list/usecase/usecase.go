@@ -3,18 +3,20 @@ package usecase
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math/big"
 	"os"
-	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/core-coin/go-core/v2/common"
 	"github.com/core-coin/go-core/v2/common/hexutil"
 	"github.com/core-coin/go-core/v2/core/types"
 	"github.com/core-coin/go-core/v2/crypto"
 	"github.com/core-coin/go-core/v2/rlp"
-	"github.com/gocarina/gocsv"
 
 	"github.com/core-coin/pigeon/domain"
 	"github.com/core-coin/pigeon/infrastructure/rpcClient"
@@ -25,42 +27,179 @@ import (
 type transactionListUsecase struct {
 	logger logger.Logger
 	rpc    rpcClient.Client
+	policy domain.PolicyUseCase
+	nonces domain.NonceReservationUseCase
+	prices domain.EnergyPriceUseCase
+	flow   domain.FlowControlUseCase
+
+	// planned are nonce ranges filled by FillTxs which are not reserved yet, reserved are the reserved ones
+	planned  []domain.NonceRange
+	reserved []domain.NonceRange
 }
 
-// NewTransactionListUsecase create new transaction list usecase
-func NewTransactionListUsecase(rpc rpcClient.Client, log logger.Logger) domain.TransactionListUseCase {
+// NewTransactionListUsecase create new transaction list usecase.
+// Policy may be nil to sign without spending policy, nonces may be nil to take pending nonces without reservation,
+// prices may be nil to ask the node for energy price of every transaction, flow may be nil to stream without limits.
+func NewTransactionListUsecase(rpc rpcClient.Client, log logger.Logger, policy domain.PolicyUseCase, nonces domain.NonceReservationUseCase, prices domain.EnergyPriceUseCase, flow domain.FlowControlUseCase) domain.TransactionListUseCase {
 	return &transactionListUsecase{
 		rpc:    rpc,
 		logger: log,
+		policy: policy,
+		nonces: nonces,
+		prices: prices,
+		flow:   flow,
 	}
 }
 
-// StreamSignedTxs is sending raw transactions to blockchain
+// StreamSignedTxs is sending raw transactions to blockchain, waiting for a free slot of the sender if flow is limited.
+// Transactions which the node already knows are counted as streamed, so an interrupted batch can be streamed again.
 func (t *transactionListUsecase) StreamSignedTxs(signedTxs []string) ([]string, error) {
+	hashes, errs := t.StreamSignedTxsByRow(signedTxs, false)
 	var txIDs []string
-	for _, tx := range signedTxs {
+	for i, hash := range hashes {
+		if errs[i] != nil {
+			return txIDs, errs[i]
+		}
+		if hash == "" {
+			break
+		}
+		txIDs = append(txIDs, hash)
+	}
+	return txIDs, nil
+}
+
+// StreamSignedTxsInLanes is streaming transactions of every sender in its own goroutine
+func (t *transactionListUsecase) StreamSignedTxsInLanes(signedTxs []string) ([]string, error) {
+	hashes, errs := t.StreamSignedTxsByRow(signedTxs, true)
+	var txIDs []string
+	for _, hash := range hashes {
+		if hash != "" {
+			txIDs = append(txIDs, hash)
+		}
+	}
+	var messages []string
+	for _, err := range errs {
+		if err != nil {
+			messages = append(messages, err.Error())
+		}
+	}
+	if len(messages) > 0 {
+		return txIDs, errors.New(strings.Join(messages, "; "))
+	}
+	return txIDs, nil
+}
+
+// StreamSignedTxsByRow is streaming transactions in one lane or in a lane per sender,
+// a lane stops at its first error and the rest of its rows are left without hash and error
+func (t *transactionListUsecase) StreamSignedTxsByRow(signedTxs []string, parallel bool) ([]string, []error) {
+	hashes := make([]string, len(signedTxs))
+	errs := make([]error, len(signedTxs))
+	if !parallel {
+		rows := make([]int, len(signedTxs))
+		for i := range rows {
+			rows[i] = i
+		}
+		t.streamLane(signedTxs, rows, hashes, errs)
+		return hashes, errs
+	}
+
+	decoded, err := t.DecodeSignedTxs(signedTxs)
+	if err != nil {
+		if len(errs) > 0 {
+			errs[0] = err
+		}
+		return hashes, errs
+	}
+	lanes := map[string][]int{}
+	var senders []string
+	for i, tx := range decoded {
+		if _, ok := lanes[tx.From]; !ok {
+			senders = append(senders, tx.From)
+		}
+		lanes[tx.From] = append(lanes[tx.From], i)
+	}
+	t.logger.Debugf("Streaming %v transactions in %v lanes", len(signedTxs), len(senders))
+
+	var wg sync.WaitGroup
+	for _, sender := range senders {
+		wg.Add(1)
+		go func(sender string, rows []int) {
+			defer wg.Done()
+			t.streamLane(signedTxs, rows, hashes, errs)
+			for _, row := range rows {
+				if errs[row] != nil {
+					errs[row] = fmt.Errorf("sender %v: %v", sender, errs[row])
+				}
+			}
+		}(sender, lanes[sender])
+	}
+	wg.Wait()
+	return hashes, errs
+}
+
+// streamLane is streaming transactions of rows in order until the first error
+func (t *transactionListUsecase) streamLane(signedTxs []string, rows []int, hashes []string, errs []error) {
+	for _, row := range rows {
+		tx := signedTxs[row]
+		if t.flow != nil {
+			decoded, err := t.DecodeSignedTxs([]string{tx})
+			if err != nil {
+				errs[row] = err
+				return
+			}
+			nonce, _ := strconv.ParseUint(decoded[0].Nonce, 10, 64)
+			err = t.flow.WaitForSlot(decoded[0].From, nonce)
+			if err != nil {
+				errs[row] = err
+				return
+			}
+		}
 		hash, err := t.rpc.SendRawTransaction(tx)
 		if err != nil {
-			return txIDs, err
+			knownHash, known := t.isKnownTx(tx)
+			if !known {
+				errs[row] = err
+				return
+			}
+			t.logger.Infof("Transaction %v was streamed before", knownHash)
+			hash = knownHash
 		}
 		t.logger.Debugf("Streamed transaction with hash %v", hash)
-		txIDs = append(txIDs, hash)
+		hashes[row] = hash
 	}
-	return txIDs, nil
 }
 
-// WriteTxIDsToFile is writing transaction hashes to file
-func (t *transactionListUsecase) WriteTxIDsToFile(txIDs []string, fileName string) error {
+// isKnownTx is checking whether the node has a signed transaction in its pool or in the chain
+func (t *transactionListUsecase) isKnownTx(signedTx string) (string, bool) {
+	hash, err := pkg.SignedTxHash(signedTx)
+	if err != nil {
+		return "", false
+	}
+	tx, err := t.rpc.GetTransactionByHash(hash)
+	if err != nil || tx == nil {
+		return "", false
+	}
+	return hash, true
+}
+
+// WriteTxIDsToFile is writing transaction hashes to file, with extra columns of their rows if there are any
+func (t *transactionListUsecase) WriteTxIDsToFile(txIDs []string, extras []map[string]string, fileName string) error {
 	if len(txIDs) == 0 {
 		t.logger.Debug("Trying to write 0 txs IDs to file")
 		return nil
 	}
 
-	data, err := json.Marshal(txIDs)
-	if err != nil {
-		return err
+	var err error
+	if pkg.IsJSONLines(pkg.OutputExt(fileName)) {
+		err = writeEntryLines(fileName, os.O_TRUNC, "hash", txIDs, extras)
+	} else {
+		var data []byte
+		data, err = encodeEntries("hash", txIDs, extras)
+		if err != nil {
+			return err
+		}
+		err = pkg.WriteOutput(fileName, data)
 	}
-	err = os.WriteFile(fileName, data, 0644)
 	if err != nil {
 		return err
 	}
@@ -85,126 +224,392 @@ func (t *transactionListUsecase) WriteTxIDsToConsole(txIDs []string) error {
 	return nil
 }
 
-// GetSignedTxsFromFile is getting raw transaction from file
-func (t *transactionListUsecase) GetSignedTxsFromFile(fileName string) ([]string, error) {
-	jsonFile, err := os.Open(fileName)
+// GetTxIDsFromFile is getting transaction hashes from file with extra columns of their rows
+func (t *transactionListUsecase) GetTxIDsFromFile(fileName string) ([]string, []map[string]string, error) {
+	if pkg.IsJSONLines(pkg.InputExt(fileName)) {
+		return readEntryLines(fileName, "hash")
+	}
+	data, err := pkg.ReadInput(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return decodeEntries(data, "hash")
+}
+
+// GetSignedTxsFromFile is getting raw transaction from file with extra columns of their rows
+func (t *transactionListUsecase) GetSignedTxsFromFile(fileName string) ([]string, []map[string]string, error) {
+	if pkg.IsJSONLines(pkg.InputExt(fileName)) {
+		return readEntryLines(fileName, "signed_tx")
+	}
+	jsonFile, err := pkg.OpenInput(fileName)
 	if err != nil {
-		return []string{}, err
+		return []string{}, nil, err
 	}
 	defer jsonFile.Close()
 
 	byteValue, err := ioutil.ReadAll(jsonFile)
 	if err != nil {
-		return []string{}, err
+		return []string{}, nil, err
 	}
+	return decodeEntries(byteValue, "signed_tx")
+}
 
-	var result []string
-	err = json.Unmarshal(byteValue, &result)
+// GetTxsFromFile is getting transactions from file and filling defaults
+func (t *transactionListUsecase) GetTxsFromFile(fileName string, csvFormat *domain.CSVFormat, startNonces map[string]uint64) (domain.TransactionList, error) {
+	txsFromFile, err := t.ReadTxsFromFile(fileName, csvFormat)
 	if err != nil {
-		return []string{}, err
+		return nil, err
 	}
-	return result, nil
+	return t.FillTxs(txsFromFile, startNonces)
+}
+
+// ReadTxsFromFile is getting transactions from file as they are
+func (t *transactionListUsecase) ReadTxsFromFile(fileName string, csvFormat *domain.CSVFormat) (domain.TransactionList, error) {
+	return t.getTxsFromFile(fileName, csvFormat)
 }
 
-// GetTxsFromFile is getting transactions from file
-func (t *transactionListUsecase) GetTxsFromFile(fileName string, missTitles bool) (domain.TransactionList, error) {
-	txsFromFile, err := t.getTxsFromFile(fileName, missTitles)
+// ReadTxsInChunks is calling handle with consecutive chunks of transactions from file.
+// JSON lines files are read lazily, so only one chunk is kept in memory, other formats are read at once.
+func (t *transactionListUsecase) ReadTxsInChunks(fileName string, csvFormat *domain.CSVFormat, size int, handle func(domain.TransactionList) error) error {
+	if pkg.IsJSONLines(pkg.InputExt(fileName)) {
+		return readTxLines(fileName, size, handle)
+	}
+	txs, err := t.getTxsFromFile(fileName, csvFormat)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	for len(txs) > 0 {
+		n := size
+		if n > len(txs) {
+			n = len(txs)
+		}
+		err = handle(txs[:n])
+		if err != nil {
+			return err
+		}
+		txs = txs[n:]
+	}
+	return nil
+}
+
+// FillTxs is setting nonce, energy price and energy limit of rows which miss them.
+// Rows without nonce continue after the previous row of the same sender, the first one starts from
+// start nonce of the sender if it is set or from the pending (or reserved) nonce.
+func (t *transactionListUsecase) FillTxs(txs domain.TransactionList, startNonces map[string]uint64) (domain.TransactionList, error) {
+	var err error
+	nextNonces := map[string]uint64{}
+	for sender, nonce := range startNonces {
+		nextNonces[pkg.NormalizeAddress(sender)] = nonce
 	}
 
-	nonces := map[string]string{}
+	// count rows which need a nonce to reserve them at once
+	missingNonces := map[string]uint64{}
+	for _, tx := range txs {
+		if tx.Nonce == "" {
+			missingNonces[pkg.NormalizeAddress(tx.From)]++
+		}
+	}
 
-	for _, tx := range txsFromFile {
+	for i, tx := range txs {
+		sender := pkg.NormalizeAddress(tx.From)
 		// set default to empty values
 		if tx.Nonce == "" {
-			if nonce, ok := nonces[tx.From]; !ok {
-				nonce, err := t.rpc.GetAccountNonce(tx.From, "pending")
-				if err != nil {
-					if err != nil {
-						panic(err)
-					}
+			nonce, ok := nextNonces[sender]
+			if !ok {
+				if t.nonces != nil {
+					nonce, err = t.nonces.NextNonce(tx.From)
+					t.planned = append(t.planned, domain.NonceRange{Sender: sender, Start: nonce, Count: missingNonces[sender]})
+				} else {
+					nonce, err = t.rpc.GetAccountNonce(tx.From, "pending")
 				}
-				tx.Nonce = strconv.FormatUint(nonce, 10)
-				nonces[tx.From] = tx.Nonce
-			} else {
-				nonceInt, err := strconv.Atoi(nonce)
 				if err != nil {
-					if err != nil {
-						panic(err)
-					}
+					return nil, err
 				}
-				tx.Nonce = strconv.FormatUint(uint64(nonceInt+1), 10)
-				nonces[tx.From] = tx.Nonce
 			}
+			tx.Nonce = strconv.FormatUint(nonce, 10)
+			nextNonces[sender] = nonce + 1
+		} else {
+			nonce, err := strconv.ParseUint(tx.Nonce, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("row %v: bad nonce %q: %v", i+1, tx.Nonce, err)
+			}
+			nextNonces[sender] = nonce + 1
 		}
 		if tx.EnergyPrice == "" {
-			energyPrice, err := t.rpc.EstimateEnergyPrice()
+			energyPrice, err := t.getEnergyPrice()
 			if err != nil {
-				panic(err)
+				return nil, err
 			}
-			tx.EnergyPrice = strconv.Itoa(int(energyPrice))
+			tx.EnergyPrice = energyPrice.String()
 		}
 		if tx.EnergyLimit == "" {
 			tx.EnergyLimit = "21000"
 		}
 
 	}
-	return txsFromFile, nil
+	return txs, nil
+}
+
+// getEnergyPrice is getting energy price by strategy if it is set or from the node
+func (t *transactionListUsecase) getEnergyPrice() (*big.Int, error) {
+	if t.prices != nil {
+		return t.prices.GetEnergyPrice()
+	}
+	energyPrice, err := t.rpc.EstimateEnergyPrice()
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(energyPrice), nil
+}
+
+// ReserveNonces is reserving all planned ranges, the ones reserved before a failure are released
+func (t *transactionListUsecase) ReserveNonces() error {
+	planned := t.planned
+	t.planned = nil
+	for _, r := range planned {
+		err := t.nonces.ReserveNonces(r)
+		if err != nil {
+			t.ReleaseNonces()
+			return err
+		}
+		t.reserved = append(t.reserved, r)
+	}
+	return nil
+}
+
+// ReleaseNonces is logging failures to release, reservations expire anyway
+func (t *transactionListUsecase) ReleaseNonces() {
+	for _, r := range t.reserved {
+		err := t.nonces.ReleaseNonces(r)
+		if err != nil {
+			t.logger.Warnf("Cannot release nonces %v-%v of sender %v: %v", r.Start, r.Start+r.Count-1, r.Sender, err)
+		}
+	}
+	t.reserved = nil
+	t.planned = nil
+}
+
+// KeepNonces is keeping every reserved range, they are not released afterwards even if keeping fails
+func (t *transactionListUsecase) KeepNonces() error {
+	reserved := t.reserved
+	t.reserved = nil
+	for _, r := range reserved {
+		err := t.nonces.KeepNonces(r)
+		if err != nil {
+			return fmt.Errorf("cannot keep nonces %v-%v of sender %v: %v", r.Start, r.Start+r.Count-1, r.Sender, err)
+		}
+	}
+	return nil
+}
+
+// RefreshNonces is logging failures to refresh, the next refresh tries again
+func (t *transactionListUsecase) RefreshNonces() {
+	for _, r := range t.reserved {
+		err := t.nonces.RefreshNonces(r)
+		if err != nil {
+			t.logger.Warnf("Cannot refresh reservation of nonces %v-%v of sender %v: %v", r.Start, r.Start+r.Count-1, r.Sender, err)
+		}
+	}
 }
 
 // SignTxs signs transactions
 func (t *transactionListUsecase) SignTxs(txs domain.TransactionList, key *crypto.PrivateKey) ([]string, error) {
+	return t.signTxs(txs, func(*domain.Transaction) (*crypto.PrivateKey, error) {
+		return key, nil
+	})
+}
+
+// SignTxsWithKeys signs every transaction with the key of its sender
+func (t *transactionListUsecase) SignTxsWithKeys(txs domain.TransactionList, keys map[string]*crypto.PrivateKey) ([]string, error) {
+	senderKeys := map[string]*crypto.PrivateKey{}
+	for sender, key := range keys {
+		senderKeys[pkg.NormalizeAddress(sender)] = key
+	}
+	return t.signTxs(txs, func(tx *domain.Transaction) (*crypto.PrivateKey, error) {
+		key, ok := senderKeys[pkg.NormalizeAddress(tx.From)]
+		if !ok {
+			return nil, fmt.Errorf("there is no key of sender %v", tx.From)
+		}
+		return key, nil
+	})
+}
+
+// signTxs is checking spending policy and signing transactions with keys chosen by keyOf
+func (t *transactionListUsecase) signTxs(txs domain.TransactionList, keyOf func(*domain.Transaction) (*crypto.PrivateKey, error)) ([]string, error) {
 	var signed []string
-	signer := types.MakeSigner(big.NewInt(int64(common.DefaultNetworkID)))
+	if t.policy != nil {
+		if err := t.policy.Check(txs); err != nil {
+			return signed, err
+		}
+	}
 	for _, internalTx := range txs {
 		tx, err := t.TxToGocoreType(internalTx)
 		if err != nil {
 			return signed, err
 		}
 
-		signedTx, err := types.SignTx(tx, signer, key)
+		key, err := keyOf(internalTx)
 		if err != nil {
 			return signed, err
 		}
-
-		signedTxBytes, err := rlp.EncodeToBytes(signedTx)
+		signedTx, err := pkg.SignTx(tx, key)
 		if err != nil {
 			return signed, err
 		}
-		signed = append(signed, hexutil.Encode(signedTxBytes))
+		signed = append(signed, signedTx)
 	}
 	return signed, nil
 }
 
-// WriteSignedTxsToFile is writing transactions to file
-func (t *transactionListUsecase) WriteSignedTxsToFile(signedTxs []string, fileName string) error {
+// WriteTxsToFile is writing unsigned transactions to file
+func (t *transactionListUsecase) WriteTxsToFile(txs domain.TransactionList, fileName string) error {
+	var data []byte
+	var err error
+	switch pkg.OutputExt(fileName) {
+	case ".jsonl", ".ndjson":
+		return writeTxLines(fileName, txs)
+	case ".json":
+		data, err = json.Marshal(txs)
+	case ".csv":
+		data, err = marshalTxsCSV(txs)
+	default:
+		return errors.New("unsupported file extension")
+	}
+	if err != nil {
+		return err
+	}
+	return pkg.WriteOutput(fileName, data)
+}
+
+// WriteSignedTxsToFile is writing transactions to file, with extra columns of their rows if there are any
+func (t *transactionListUsecase) WriteSignedTxsToFile(signedTxs []string, extras []map[string]string, fileName string) error {
 	if len(signedTxs) == 0 {
 		t.logger.Debug("Trying to write 0 signed txs to file")
 		return nil
 	}
+	if pkg.IsJSONLines(pkg.OutputExt(fileName)) {
+		return writeEntryLines(fileName, os.O_TRUNC, "signed_tx", signedTxs, extras)
+	}
 
-	data, err := json.Marshal(signedTxs)
+	data, err := encodeEntries("signed_tx", signedTxs, extras)
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(fileName, data, 0644)
+	return pkg.WriteOutput(fileName, data)
+}
+
+// AppendSignedTxsToFile is adding transactions to the end of JSON lines file
+func (t *transactionListUsecase) AppendSignedTxsToFile(signedTxs []string, extras []map[string]string, fileName string) error {
+	if !pkg.IsJSONLines(pkg.OutputExt(fileName)) {
+		return errors.New("only JSON lines files (.jsonl, .ndjson) can be appended")
+	}
+	return writeEntryLines(fileName, os.O_APPEND, "signed_tx", signedTxs, extras)
+}
+
+// DecodeSignedTxs is converting raw transactions back to transaction list
+func (t *transactionListUsecase) DecodeSignedTxs(signedTxs []string) (domain.TransactionList, error) {
+	var txs domain.TransactionList
+	signer := types.MakeSigner(big.NewInt(int64(common.DefaultNetworkID)))
+	for i, signedTx := range signedTxs {
+		raw, err := hexutil.Decode(strings.TrimSpace(signedTx))
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", i+1, err)
+		}
+		tx := new(types.Transaction)
+		err = rlp.DecodeBytes(raw, tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", i+1, err)
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %v: %v", i+1, err)
+		}
+		var to string
+		if tx.To() != nil {
+			to = tx.To().Hex()
+		}
+		txs = append(txs, &domain.Transaction{
+			From:        from.Hex(),
+			To:          to,
+			Amount:      domain.NewAmount(tx.Value()),
+			EnergyLimit: strconv.FormatUint(tx.Energy(), 10),
+			EnergyPrice: tx.EnergyPrice().String(),
+			Nonce:       strconv.FormatUint(tx.Nonce(), 10),
+		})
+	}
+	return txs, nil
+}
+
+// Summarize is computing batch summary with given number of the largest payments
+func (t *transactionListUsecase) Summarize(txs domain.TransactionList, largest int) (*domain.BatchSummary, error) {
+	summary := &domain.BatchSummary{
+		Count:        len(txs),
+		Total:        new(big.Int),
+		MaxFees:      new(big.Int),
+		SenderTotals: map[string]*big.Int{},
+	}
+	for i, tx := range txs {
+		amount := tx.Amount.Ore()
+		summary.Total.Add(summary.Total, amount)
+		if _, ok := summary.SenderTotals[tx.From]; !ok {
+			summary.SenderTotals[tx.From] = new(big.Int)
+		}
+		summary.SenderTotals[tx.From].Add(summary.SenderTotals[tx.From], amount)
+
+		limit, ok := new(big.Int).SetString(tx.EnergyLimit, 10)
+		if !ok {
+			return nil, fmt.Errorf("transaction %v: energy limit has bad number", i+1)
+		}
+		price, ok := new(big.Int).SetString(tx.EnergyPrice, 10)
+		if !ok {
+			return nil, fmt.Errorf("transaction %v: energy price has bad number", i+1)
+		}
+		summary.MaxFees.Add(summary.MaxFees, limit.Mul(limit, price))
+	}
+
+	sorted := make(domain.TransactionList, len(txs))
+	copy(sorted, txs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Amount.Cmp(sorted[j].Amount) > 0
+	})
+	if len(sorted) > largest {
+		sorted = sorted[:largest]
+	}
+	summary.Largest = sorted
+	return summary, nil
 }
 
 // getTxsFromFile is loading transactions from file and choose method depending on file extension
-func (t *transactionListUsecase) getTxsFromFile(fileName string, missTitles bool) ([]*domain.Transaction, error) {
-	switch filepath.Ext(fileName) {
+func (t *transactionListUsecase) getTxsFromFile(fileName string, csvFormat *domain.CSVFormat) ([]*domain.Transaction, error) {
+	switch pkg.InputExt(fileName) {
 	case ".json":
 		return t.getTxsFromJSON(fileName)
 	case ".csv":
-		return t.getTxsFromCSV(fileName, missTitles)
+		return t.getTxsFromCSV(fileName, csvFormat)
+	case ".xlsx":
+		return t.getTxsFromXLSX(fileName, csvFormat)
+	case ".jsonl", ".ndjson":
+		return t.getTxsFromJSONLines(fileName)
 	}
 	return nil, errors.New("unsupported file extension")
 }
 
+// getTxsFromJSONLines is loading transactions from JSON lines file
+func (t *transactionListUsecase) getTxsFromJSONLines(fileName string) ([]*domain.Transaction, error) {
+	var txs []*domain.Transaction
+	err := readTxLines(fileName, 1000, func(chunk domain.TransactionList) error {
+		txs = append(txs, chunk...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return txs, nil
+}
+
 // getTxsFromJSON is loading transactions from json file
 func (t *transactionListUsecase) getTxsFromJSON(fileName string) ([]*domain.Transaction, error) {
-	jsonFile, err := os.Open(fileName)
+	jsonFile, err := pkg.OpenInput(fileName)
 	if err != nil {
 		return nil, err
 	}
@@ -223,29 +628,6 @@ func (t *transactionListUsecase) getTxsFromJSON(fileName string) ([]*domain.Tran
 	return txs, nil
 }
 
-// getTxsFromCSV is loading transaction from csv file
-func (t *transactionListUsecase) getTxsFromCSV(fileName string, missTitles bool) ([]*domain.Transaction, error) {
-	in, err := os.Open(fileName)
-	if err != nil {
-		return nil, err
-	}
-	defer in.Close()
-
-	txs := []*domain.Transaction{}
-
-	read := gocsv.DefaultCSVReader(in)
-	if !missTitles {
-		if err := gocsv.UnmarshalCSVWithoutHeaders(read, &txs); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := gocsv.UnmarshalCSV(read, &txs); err != nil {
-			return nil, err
-		}
-	}
-	return txs, nil
-}
-
 // TxToGocoreType converts *domain.Transaction type to gocore *types.Transaction type
 func (t *transactionListUsecase) TxToGocoreType(tx *domain.Transaction) (*types.Transaction, error) {
 	to, err := common.HexToAddress(tx.To)
@@ -268,16 +650,7 @@ func (t *transactionListUsecase) TxToGocoreType(tx *domain.Transaction) (*types.
 		return nil, errors.New("energy price in transaction has bad number ")
 	}
 
-	bigval := new(big.Float)
-	bigval.SetFloat64(tx.Amount)
-
-	coin := new(big.Float)
-	coin.SetInt(pkg.Core)
-
-	bigval.Mul(bigval, coin)
-
-	result := new(big.Int)
-	bigval.Int(result)
+	result := tx.Amount.Ore()
 
 	gocoreTx := types.NewTransaction(uint64(nonce), to, result, uint64(limit), price, []byte{})
 	t.logger.Debugf("Converted transaction from: %+v to %+v", tx, gocoreTx)
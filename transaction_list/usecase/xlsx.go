@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/core-coin/pigeon/domain"
+	"github.com/core-coin/pigeon/pkg"
+)
+
+// getTxsFromXLSX is loading transactions from a sheet of Excel workbook with the same layout rules as CSV
+func (t *transactionListUsecase) getTxsFromXLSX(fileName string, format *domain.CSVFormat) ([]*domain.Transaction, error) {
+	if format == nil {
+		format = &domain.CSVFormat{}
+	}
+	in, err := pkg.OpenInput(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	book, err := excelize.OpenReader(in)
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+
+	sheet, err := getSheet(book, format.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	// raw values are not rounded or formatted by cell styles
+	rows, err := book.GetRows(sheet, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	t.logger.Debugf("Reading %v rows of sheet %q", len(rows), sheet)
+
+	i := 0
+	return readTable(func() ([]string, int, error) {
+		for i < len(rows) {
+			row := rows[i]
+			i++
+			if format.Comment != 0 && len(row) > 0 && strings.HasPrefix(strings.TrimSpace(row[0]), string(format.Comment)) {
+				continue
+			}
+			for column, value := range row {
+				row[column], err = getXLSXNumber(book, sheet, column, i, value)
+				if err != nil {
+					return nil, 0, err
+				}
+			}
+			return row, i, nil
+		}
+		return nil, 0, io.EOF
+	}, format)
+}
+
+// getSheet is finding sheet by name or 1-based number, first sheet if name is not set
+func getSheet(book *excelize.File, name string) (string, error) {
+	sheets := book.GetSheetList()
+	if len(sheets) == 0 {
+		return "", fmt.Errorf("workbook has no sheets")
+	}
+	if name == "" {
+		return sheets[0], nil
+	}
+	for _, sheet := range sheets {
+		if strings.EqualFold(sheet, name) {
+			return sheet, nil
+		}
+	}
+	if number, err := strconv.Atoi(name); err == nil && number >= 1 && number <= len(sheets) {
+		return sheets[number-1], nil
+	}
+	return "", fmt.Errorf("sheet %q is not found, sheets are %v", name, strings.Join(sheets, ", "))
+}
+
+// getXLSXNumber is writing numeric cells in plain decimal notation. Digits are kept as stored, so amounts are read
+// with full precision, and exponent form like 1.5E-7 is expanded exactly. Text cells are returned as they are.
+func getXLSXNumber(book *excelize.File, sheet string, column, row int, value string) (string, error) {
+	if !strings.ContainsAny(value, "eE") {
+		return value, nil
+	}
+	number, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return value, nil
+	}
+	cell, err := excelize.CoordinatesToCellName(column+1, row)
+	if err != nil {
+		return "", err
+	}
+	cellType, err := book.GetCellType(sheet, cell)
+	if err != nil {
+		return "", err
+	}
+	if cellType != excelize.CellTypeUnset && cellType != excelize.CellTypeNumber {
+		return value, nil
+	}
+	// a decimal number has a finite number of decimals
+	decimals := 0
+	for scaled := new(big.Rat).Set(number); !scaled.IsInt(); decimals++ {
+		scaled.Mul(scaled, big.NewRat(10, 1))
+	}
+	return number.FloatString(decimals), nil
+}